@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 
 	"github.com/internet-computer/oko/commands"
 	"github.com/internet-computer/oko/internal/cmd"
@@ -13,6 +15,8 @@ const VERSION = "v0.0.0"
 var Oko = cmd.Command{
 	Name:    "oko",
 	Summary: "A Package Manager",
+	Options: commands.GlobalOptions,
+	Before:  commands.ApplyGlobalOptions,
 	Commands: append(
 		[]cmd.Command{
 			VersionCommand,
@@ -37,6 +41,11 @@ func main() {
 		return
 	}
 	if err := Oko.Call(os.Args[1:]...); err != nil {
+		// Propagate the exit code of executed tools.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
 		fmt.Printf("ERROR: %s\n", err)
 		os.Exit(1)
 	}
@@ -8,5 +8,5 @@ import (
 )
 
 func main() {
-	fmt.Println(cmd.Manual(commands.Commands))
+	fmt.Println(cmd.Manual(commands.Commands, commands.GlobalOptions...))
 }
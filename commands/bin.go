@@ -1,15 +1,16 @@
 package commands
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
 
 	"github.com/internet-computer/oko/config"
 	"github.com/internet-computer/oko/github"
+	"github.com/internet-computer/oko/internal/checksum"
 	"github.com/internet-computer/oko/internal/cmd"
 	"github.com/internet-computer/oko/internal/tar"
 )
@@ -28,6 +29,10 @@ var BinDownloadCommand = cmd.Command{
 	Name:    "download",
 	Aliases: []string{"d"},
 	Summary: "downloads the Motoko compiler",
+	Description: "Downloads the Motoko compiler and didc versions specified in the Oko package file.\n\n" +
+		"If no didc version is specified, `--didc` pins the latest release of didc in the package file.\n\n" +
+		"Downloads are verified against the published SHA-256 checksums, if available, and the checksums pinned in the Oko package file. " +
+		"Checksums that are not pinned yet get added to the package file.",
 	Options: []cmd.Option{
 		{
 			Name:     "didc",
@@ -35,70 +40,43 @@ var BinDownloadCommand = cmd.Command{
 		},
 	},
 	Method: func(_ []string, options map[string]string) error {
-		pkg, err := config.LoadPackageState("./oko.json")
+		l, err := config.LockManifest(config.ManifestPath())
 		if err != nil {
 			return NewBinError(err)
 		}
+		defer l.Unlock()
 
-		if pkg.CompilerVersion == nil {
-			return NewBinError(NewCompilerVersionNotFoundError())
-		}
-		version := *pkg.CompilerVersion
-
-		goos := runtime.GOOS // TODO: improve w/ GOARCH
-		switch goos {
-		case "darwin":
-			goos = "macos"
-		case "linux":
-			goos = "linux64"
-		default:
-			return NewBinError(NewUnsupportedRuntimeErrors(goos))
-		}
-
-		if err := tar.DownloadGz(
-			fmt.Sprintf(
-				"https://github.com/dfinity/motoko/releases/download/%s/motoko-%s-%s.tar.gz",
-				version, goos, version,
-			),
-			fmt.Sprintf(".oko/bin/%s", version),
-		); err != nil {
+		pkg, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
 			return NewBinError(err)
 		}
 
-		if _, ok := options["didc"]; ok {
-			url := "dfinity/candid"
-			resp, err := http.Get(fmt.Sprintf("https://api.github.com/repos/%s/releases", url))
+		if _, ok := options["didc"]; ok && pkg.DidcVersion == nil {
+			release, err := github.LatestRelease("dfinity/candid")
 			if err != nil {
 				return NewBinError(err)
 			}
-			data, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return NewBinError(err)
-			}
-			var releases []github.Release
-			if err := json.Unmarshal(data, &releases); err != nil {
-				return NewBinError(err)
-			}
-			if len(releases) == 0 {
-				return NewBinError(github.NewReleasesNotFoundErrors(url))
-			}
+			pkg.DidcVersion = &release.TagName
+		}
+		if pkg.CompilerVersion == nil && pkg.DidcVersion == nil {
+			return NewBinError(NewCompilerVersionNotFoundError())
+		}
 
-			resp, err = http.Get(fmt.Sprintf(
-				"https://github.com/%s/releases/download/%s/didc-%s",
-				url, releases[0].TagName, goos,
-			))
-			if err != nil {
+		if pkg.CompilerVersion != nil {
+			if err := downloadCompiler(pkg); err != nil {
 				return NewBinError(err)
 			}
-			data, err = io.ReadAll(resp.Body)
-			if err != nil {
-				return NewBinError(err)
-			}
-			if err := os.WriteFile(fmt.Sprintf(".oko/bin/%s/didc", version), data, os.ModePerm); err != nil {
+		}
+		if pkg.DidcVersion != nil {
+			if err := downloadDidc(pkg); err != nil {
 				return NewBinError(err)
 			}
 		}
 
+		// Pin the checksums (and didc version) of the downloaded files.
+		if err := pkg.Save(config.ManifestPath()); err != nil {
+			return NewBinError(err)
+		}
 		return nil
 	},
 }
@@ -107,21 +85,221 @@ var BinShowCommand = cmd.Command{
 	Name:    "show",
 	Aliases: []string{"s"},
 	Summary: "prints out the path to the bin dir",
+	Description: "Prints out the path to the bin dir of the Motoko compiler.\n\n" +
+		"If a tool is specified (e.g. `moc`, `mo-doc` or `didc`), the path to the tool is printed instead.",
+	Options: []cmd.Option{
+		{
+			Name:     "tool",
+			Summary:  "tool name",
+			HasValue: true,
+		},
+	},
 	Method: func(args []string, options map[string]string) error {
-		pkg, err := config.LoadPackageState("./oko.json")
+		pkg, err := config.LoadPackageState(config.ManifestPath())
 		if err != nil {
 			return NewBinError(err)
 		}
 
+		if tool, ok := options["tool"]; ok && tool == "didc" {
+			if pkg.DidcVersion == nil {
+				return NewBinError(NewDidcVersionNotFoundError())
+			}
+			fmt.Print(didcPath(*pkg.DidcVersion))
+			return nil
+		}
+
 		if pkg.CompilerVersion == nil {
 			return NewBinError(NewCompilerVersionNotFoundError())
 		}
-		version := *pkg.CompilerVersion
-		fmt.Printf(".oko/bin/%s", version)
+		dir := compilerDir(*pkg.CompilerVersion)
+		if tool, ok := options["tool"]; ok {
+			fmt.Print(filepath.Join(dir, tool))
+			return nil
+		}
+		fmt.Print(dir)
 		return nil
 	},
 }
 
+// compilerDir returns the directory of the Motoko compiler with the given version.
+func compilerDir(version string) string {
+	return config.Store().CompilerDir(version)
+}
+
+// compilerTool returns the path to the given tool of the Motoko compiler.
+// Downloads the compiler if it is not present yet, which also pins its checksum.
+func compilerTool(pkg *config.PackageState, tool string) (string, error) {
+	if pkg.CompilerVersion == nil {
+		return "", NewCompilerVersionNotFoundError()
+	}
+	path := filepath.Join(compilerDir(*pkg.CompilerVersion), tool)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	l, err := config.LockManifest(config.ManifestPath())
+	if err != nil {
+		return "", err
+	}
+	defer l.Unlock()
+
+	// Reload the package state, so that changes made since it was loaded are
+	// not lost when pinning the checksum.
+	if pkg, err = config.LoadPackageState(config.ManifestPath()); err != nil {
+		return "", err
+	}
+	if pkg.CompilerVersion == nil {
+		return "", NewCompilerVersionNotFoundError()
+	}
+	path = filepath.Join(compilerDir(*pkg.CompilerVersion), tool)
+	if err := downloadCompiler(pkg); err != nil {
+		return "", err
+	}
+	if err := pkg.Save(config.ManifestPath()); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", NewPathNotFoundError(path)
+	}
+	return path, nil
+}
+
+// didcPath returns the path to didc with the given version.
+func didcPath(version string) string {
+	return config.Store().DidcPath(version)
+}
+
+// didcTool returns the path to didc. Downloads didc if it is not present yet,
+// which also pins its checksum.
+func didcTool(pkg *config.PackageState) (string, error) {
+	if pkg.DidcVersion == nil {
+		return "", NewDidcVersionNotFoundError()
+	}
+	path := didcPath(*pkg.DidcVersion)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	l, err := config.LockManifest(config.ManifestPath())
+	if err != nil {
+		return "", err
+	}
+	defer l.Unlock()
+
+	// Reload the package state, see compilerTool.
+	if pkg, err = config.LoadPackageState(config.ManifestPath()); err != nil {
+		return "", err
+	}
+	if pkg.DidcVersion == nil {
+		return "", NewDidcVersionNotFoundError()
+	}
+	path = didcPath(*pkg.DidcVersion)
+	if err := downloadDidc(pkg); err != nil {
+		return "", err
+	}
+	if err := pkg.Save(config.ManifestPath()); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// download fetches the file at the given url and verifies it against both the
+// published checksum (`{url}.sha256`), if available, and the checksum pinned in
+// the package state. The checksum gets pinned if it is not pinned yet.
+func download(pkg *config.PackageState, url string) ([]byte, error) {
+	raw, err := tar.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := tar.Fetch(fmt.Sprintf("%s.sha256", url))
+	if err != nil {
+		var statusErr *tar.UnexpectedStatusCodeError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+		// No checksum published.
+	} else {
+		sum, err := checksum.Parse(published)
+		if err != nil {
+			return nil, err
+		}
+		if err := checksum.Verify(url, raw, sum); err != nil {
+			return nil, err
+		}
+	}
+
+	if sum, ok := pkg.Checksums[url]; ok {
+		if err := checksum.Verify(url, raw, sum); err != nil {
+			return nil, err
+		}
+	} else {
+		pkg.Checksums[url] = checksum.Sum(raw)
+	}
+	return raw, nil
+}
+
+// downloadCompiler downloads the Motoko compiler specified in the package state.
+func downloadCompiler(pkg *config.PackageState) error {
+	goos, err := platform()
+	if err != nil {
+		return err
+	}
+	l, err := config.LockStore()
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	version := *pkg.CompilerVersion
+	raw, err := download(pkg, fmt.Sprintf(
+		"%s/dfinity/motoko/releases/download/%s/motoko-%s-%s.tar.gz",
+		github.URL, version, goos, version,
+	))
+	if err != nil {
+		return err
+	}
+	return tar.ExtractGz(raw, compilerDir(version))
+}
+
+// downloadDidc downloads didc specified in the package state.
+func downloadDidc(pkg *config.PackageState) error {
+	goos, err := platform()
+	if err != nil {
+		return err
+	}
+	l, err := config.LockStore()
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	version := *pkg.DidcVersion
+	raw, err := download(pkg, fmt.Sprintf(
+		"%s/dfinity/candid/releases/download/%s/didc-%s",
+		github.URL, version, goos,
+	))
+	if err != nil {
+		return err
+	}
+	path := didcPath(version)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o755)
+}
+
+// platform returns the platform name used in the release assets.
+func platform() (string, error) {
+	goos := runtime.GOOS // TODO: improve w/ GOARCH
+	switch goos {
+	case "darwin":
+		return "macos", nil
+	case "linux":
+		return "linux64", nil
+	default:
+		return "", NewUnsupportedRuntimeErrors(goos)
+	}
+}
+
 type BinError struct {
 	Err error
 }
@@ -146,6 +324,16 @@ func (e CompilerVersionNotFoundError) Error() string {
 	return "no compiler version specified"
 }
 
+type DidcVersionNotFoundError struct{}
+
+func NewDidcVersionNotFoundError() *DidcVersionNotFoundError {
+	return &DidcVersionNotFoundError{}
+}
+
+func (e DidcVersionNotFoundError) Error() string {
+	return "no didc version specified"
+}
+
 type UnsupportedRuntimeErrors struct {
 	GOOS string
 }
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+var BuildCommand = cmd.Command{
+	Name:    "build",
+	Summary: "build Motoko canisters",
+	Description: "Builds the given entry file (or named build target) with the Motoko compiler specified in the Oko package file.\n\n" +
+		"Build targets can be declared in the `targets` field of the package file, their paths are relative to the directory of the package file. " +
+		"The compiler gets downloaded if it is not present yet. " +
+		"All arguments after `--` are passed to the compiler.",
+	Args:     []string{"target"},
+	Variadic: true,
+	Options: []cmd.Option{
+		{
+			Name:     "output",
+			Summary:  "output wasm file",
+			HasValue: true,
+		},
+	},
+	Method: func(args []string, options map[string]string) error {
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewBuildError(err)
+		}
+
+		target, ok := state.Targets[args[0]]
+		if ok {
+			// The paths of named targets are relative to the root directory.
+			target.Entry = config.Path(target.Entry)
+			if target.Output != "" {
+				target.Output = config.Path(target.Output)
+			}
+		} else {
+			// Not a named target, assume it is an entry file.
+			if _, err := os.Stat(args[0]); err != nil {
+				return NewBuildError(NewPathNotFoundError(args[0]))
+			}
+			target = config.BuildTarget{
+				Entry: args[0],
+			}
+		}
+		if output, ok := options["output"]; ok {
+			target.Output = output
+		}
+
+		moc, err := compilerTool(state, "moc")
+		if err != nil {
+			return NewBuildError(err)
+		}
+
+		build := exec.Command(moc, buildArguments(state, target, args[1:])...)
+		build.Stdin = os.Stdin
+		build.Stdout = os.Stdout
+		build.Stderr = os.Stderr
+		if err := build.Run(); err != nil {
+			return NewBuildError(err)
+		}
+		return nil
+	},
+}
+
+// buildArguments returns the compiler arguments to build the given target.
+func buildArguments(state *config.PackageState, target config.BuildTarget, extra []string) []string {
+	args := packageSources(state)
+	args = append(args, target.Flags...)
+	args = append(args, extra...)
+	if target.Output != "" {
+		args = append(args, "-o", target.Output)
+	}
+	return append(args, target.Entry)
+}
+
+type BuildError struct {
+	Err error
+}
+
+func NewBuildError(err error) *BuildError {
+	return &BuildError{
+		Err: err,
+	}
+}
+
+func (e BuildError) Error() string {
+	return fmt.Sprintf("build error: %s", e.Err)
+}
+
+func (e BuildError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+	"github.com/internet-computer/oko/internal/lock"
+	"golang.org/x/exp/slices"
+)
+
+var CleanCommand = cmd.Command{
+	Name:    "clean",
+	Summary: "remove unused packages",
+	Description: "Removes all packages and toolchains in the store that are not referenced by the Oko package file.\n\n" +
+		"The store is the `.oko` directory, unless `$OKO_HOME` or `--store` is set. " +
+		"A shared store keeps the packages of every package file that downloaded into it. " +
+		"Use `--dry-run` to list what would be removed and `--all` to remove the whole store, which is only allowed for the `.oko` directory.\n\n" +
+		"Packages downloaded by older versions of Oko into `.oko/{repo}-{version}` are no longer used and get removed.",
+	Options: []cmd.Option{
+		{
+			Name:     "dry-run",
+			HasValue: false,
+		},
+		{
+			Name:     "all",
+			HasValue: false,
+		},
+	},
+	Method: func(_ []string, options map[string]string) error {
+		_, dryRun := options["dry-run"]
+		unlock, err := lockStoreUsers()
+		if err != nil {
+			return NewCleanError(err)
+		}
+		defer unlock()
+
+		if _, ok := options["all"]; ok {
+			if !localStore() {
+				return NewCleanError(NewSharedStoreError(config.Store().Root))
+			}
+			return clean([]string{config.Store().Root}, dryRun)
+		}
+
+		keep, err := usedStorePaths()
+		if err != nil {
+			return NewCleanError(err)
+		}
+		stale, err := config.Store().Stale(keep)
+		if err != nil {
+			return NewCleanError(err)
+		}
+		return clean(stale, dryRun)
+	},
+}
+
+// clean removes the given paths, or only prints them on a dry run.
+func clean(paths []string, dryRun bool) error {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if dryRun {
+			fmt.Printf("would remove %s\n", path)
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return NewCleanError(err)
+		}
+		fmt.Printf("removed %s\n", path)
+	}
+	return nil
+}
+
+// localStore returns whether the store is the `.oko` directory of the package.
+func localStore() bool {
+	root, rootErr := filepath.Abs(config.Store().Root)
+	local, localErr := filepath.Abs(config.Path(".oko"))
+	return rootErr == nil && localErr == nil && root == local
+}
+
+// storePaths returns the paths in the store that are used by the given state.
+func storePaths(state *config.PackageState) []string {
+	var paths []string
+	for _, dep := range state.Dependencies {
+		paths = append(paths, dep.RelativePath())
+	}
+	for _, dep := range state.TransitiveDependencies {
+		paths = append(paths, dep.RelativePath())
+	}
+	if state.CompilerVersion != nil {
+		paths = append(paths, compilerDir(*state.CompilerVersion))
+	}
+	if state.DidcVersion != nil {
+		paths = append(paths, filepath.Dir(didcPath(*state.DidcVersion)))
+	}
+	return paths
+}
+
+// lockStoreUsers acquires the locks on the package files returned by
+// storeUsers, and then on the store, so that no packages get downloaded or
+// referenced while cleaning the store. Returns a function that releases the
+// locks.
+func lockStoreUsers() (func(), error) {
+	for {
+		manifests, err := storeUsers()
+		if err != nil {
+			return nil, err
+		}
+		var locks []*lock.Lock
+		unlock := func() {
+			for i := len(locks) - 1; i >= 0; i-- {
+				_ = locks[i].Unlock()
+			}
+		}
+		for _, manifest := range manifests {
+			l, err := config.LockManifest(manifest)
+			if err != nil {
+				unlock()
+				return nil, err
+			}
+			locks = append(locks, l)
+		}
+		l, err := config.LockStore()
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		locks = append(locks, l)
+
+		// Package files might have been registered before the store was locked.
+		registered, err := storeUsers()
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		locked := true
+		for _, manifest := range registered {
+			if !slices.Contains(manifests, manifest) {
+				locked = false
+			}
+		}
+		if locked {
+			return unlock, nil
+		}
+		unlock()
+	}
+}
+
+// storeUsers returns the absolute paths of the package file and of all other
+// package files that are registered in the store, sorted.
+func storeUsers() ([]string, error) {
+	manifests, err := config.Store().Manifests()
+	if err != nil {
+		return nil, err
+	}
+	current, err := filepath.Abs(config.ManifestPath())
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(manifests, current) {
+		manifests = append(manifests, current)
+	}
+	sort.Strings(manifests)
+	return manifests, nil
+}
+
+// usedStorePaths returns the paths in the store that are used by the package
+// file, or by any other package file that is registered in the store.
+func usedStorePaths() ([]string, error) {
+	manifests, err := storeUsers()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, manifest := range manifests {
+		state, err := config.LoadPackageState(manifest)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, storePaths(state)...)
+	}
+	return paths, nil
+}
+
+type SharedStoreError struct {
+	Root string
+}
+
+func NewSharedStoreError(root string) *SharedStoreError {
+	return &SharedStoreError{
+		Root: root,
+	}
+}
+
+func (e SharedStoreError) Error() string {
+	return fmt.Sprintf("refusing to remove the store at %q, it is not the `.oko` directory of the package", e.Root)
+}
+
+type CleanError struct {
+	Err error
+}
+
+func NewCleanError(err error) *CleanError {
+	return &CleanError{
+		Err: err,
+	}
+}
+
+func (e CleanError) Error() string {
+	return fmt.Sprintf("clean error: %s", e.Err)
+}
+
+func (e CleanError) Unwrap() error {
+	return e.Err
+}
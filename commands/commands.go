@@ -7,7 +7,20 @@ var Commands = []cmd.Command{
 	DownloadCommand,
 	InstallCommand,
 	RemoveCommand,
+	UpdateCommand,
 	MigrateCommand,
+	ExportCommand,
 	SourcesCommand,
 	BinCommand,
+	BuildCommand,
+	TestCommand,
+	RunCommand,
+	ExecCommand,
+	VerifyCommand,
+	CleanCommand,
+	DoctorCommand,
+	ListCommand,
+	TreeCommand,
+	InfoCommand,
+	SearchCommand,
 }
@@ -1,11 +1,25 @@
 package commands_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/internet-computer/oko/commands"
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/config/schema"
+	"github.com/internet-computer/oko/internal/checksum"
+	"github.com/internet-computer/oko/internal/cmd"
+	"github.com/internet-computer/oko/internal/githubtest"
+	"github.com/internet-computer/oko/store"
 )
 
 const TEST_DIR = "e2e"
@@ -18,9 +32,75 @@ func TestCommands(t *testing.T) {
 			{"Install Local", okoInstallLocal},
 			{"Remove Local", okoRemoveLocal},
 		},
+		{
+			{"Subdirectory", okoSubdirectory},
+		},
+		{
+			{"Init Metadata", okoInitMetadata},
+		},
+		{
+			{"Init", okoInit},
+			{"Install Oko", okoInstallOko},
+		},
+		{
+			{"Init", okoInit},
+			{"Install GitHub", okoInstallGitHub},
+		},
+		{
+			{"Init", okoInit},
+			{"Install Mops", okoInstallMops},
+		},
+		{
+			{"Init", okoInit},
+			{"Update", okoUpdate},
+		},
+		{
+			{"Bin Download", okoBinDownload},
+		},
+		{
+			{"Init", okoInit},
+			{"Store", okoStore},
+		},
 		{
 			{"Migrate", okoMigrate},
 		},
+		{
+			{"Migrate Mops", okoMigrateMops},
+		},
+		{
+			{"Migrate Schema", okoMigrateSchema},
+		},
+		{
+			{"Export", okoExport},
+		},
+		{
+			{"Build", okoBuild},
+		},
+		{
+			{"Test", okoTest},
+		},
+		{
+			{"Run", okoRun},
+		},
+		{
+			{"Exec", okoExec},
+		},
+		{
+			{"Verify", okoVerify},
+		},
+		{
+			{"Clean", okoClean},
+		},
+		{
+			{"Clean Shared", okoCleanShared},
+		},
+		{
+			{"Init", okoInit},
+			{"Doctor", okoDoctor},
+		},
+		{
+			{"List", okoList},
+		},
 	} {
 		for _, test := range tests {
 			t.Run(test.Name, test.T)
@@ -31,9 +111,258 @@ func TestCommands(t *testing.T) {
 
 func cleanupFiles() {
 	_ = os.Remove("./oko.json")
+	_ = os.RemoveAll("./.oko")
+	_ = os.Remove("./main.mo")
+	_ = os.Remove("./moc.out")
+	_ = os.RemoveAll("./test")
+	_ = os.Remove("./junit.xml")
 	_ = os.RemoveAll("./src")
 	_ = os.Remove("./vessel.dhall")
 	_ = os.Remove("./package-set.dhall")
+	_ = os.Remove("./mops.toml")
+}
+
+// captureStdout calls the given command and returns everything it writes to
+// stdout.
+func captureStdout(t *testing.T, command cmd.Command, args ...string) (string, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		raw, _ := io.ReadAll(r)
+		out <- string(raw)
+	}()
+	err = command.Call(args...)
+	os.Stdout = stdout
+	_ = w.Close()
+	return <-out, err
+}
+
+// fakeCompiler installs a fake `moc` that runs the given script.
+func fakeCompiler(t *testing.T, version, script string) {
+	dir := config.Store().CompilerDir(version)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(
+		fmt.Sprintf("%s/moc", dir),
+		[]byte(fmt.Sprintf("#!/bin/sh\n%s\n", script)),
+		0o755,
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func okoBuild(t *testing.T) {
+	if err := commands.InitCommand.Call("--compiler=0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	fakeCompiler(t, "0.0.0", `echo "$@" > moc.out`)
+	if err := commands.BuildCommand.Call("main.mo"); err == nil {
+		t.Fatal()
+	}
+	if err := os.WriteFile("main.mo", nil, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.BuildCommand.Call("main.mo", "--output=main.wasm", "--", "--release"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile("moc.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.TrimSpace(string(out)); s != "--release -o main.wasm main.mo" {
+		t.Error(s)
+	}
+}
+
+func okoClean(t *testing.T) {
+	if err := commands.InitCommand.Call("--compiler=0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{
+		".oko/bin/0.0.0",
+		".oko/bin/0.0.1",
+		".oko/base-0.1.0/src",
+	} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := commands.CleanCommand.Call("--dry-run"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(".oko/base-0.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.CleanCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	for dir, exists := range map[string]bool{
+		".oko/bin/0.0.0":  true,
+		".oko/bin/0.0.1":  false,
+		".oko/base-0.1.0": false,
+	} {
+		if _, err := os.Stat(dir); (err == nil) != exists {
+			t.Error(dir, err)
+		}
+	}
+	if err := commands.CleanCommand.Call("--all"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(".oko"); err == nil {
+		t.Fatal()
+	}
+}
+
+func okoCleanShared(t *testing.T) {
+	t.Setenv(store.EnvHome, t.TempDir())
+	server := githubtest.NewServer(t)
+	server.Install(t)
+	for _, repo := range []string{"org/base", "org/lib"} {
+		server.AddArchive(repo, "v0.1.0", map[string]string{
+			"src/Lib.mo": "module {}",
+		})
+	}
+
+	// Another package that shares the store.
+	other := filepath.Join(t.TempDir(), "oko.json")
+	config.SetManifest(other)
+	defer config.SetManifest("")
+	if err := commands.InitCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.InstallCommand.Call("github", "org/lib", "v0.1.0", "--name=lib"); err != nil {
+		t.Fatal(err)
+	}
+	config.SetManifest("")
+
+	if err := commands.InitCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.InstallCommand.Call("github", "org/base", "v0.1.0", "--name=base"); err != nil {
+		t.Fatal(err)
+	}
+	base := config.Store().PackageDir(server.Repository("org/base"), "v0.1.0")
+	lib := config.Store().PackageDir(server.Repository("org/lib"), "v0.1.0")
+	if err := commands.CleanCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{base, lib} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Cleaning waits for the other package file to be unlocked.
+	l, err := config.LockManifest(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- commands.CleanCommand.Call("--dry-run")
+	}()
+	select {
+	case err := <-done:
+		t.Fatal("clean did not wait for the lock", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// The shared store is never removed as a whole.
+	var sharedErr *commands.SharedStoreError
+	if err := commands.CleanCommand.Call("--all"); !errors.As(err, &sharedErr) {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(config.Store().Root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Packages of removed package files are no longer kept.
+	if err := os.Remove(other); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.CleanCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(base); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(lib); !os.IsNotExist(err) {
+		t.Error(err)
+	}
+}
+
+func okoDoctor(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.Install(t)
+	server.AddArchive("org/lib", "v0.1.0", map[string]string{
+		"src/Lib.mo": "module {}",
+	})
+	if err := commands.InstallCommand.Call("github", "org/lib", "v0.1.0", "--name=lib"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir("src", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.InstallCommand.Call("local", "src", "--name=src"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := captureStdout(t, commands.DoctorCommand)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	// Break the package file, without making it invalid.
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Dependencies["lib"].Dependencies = []string{"base"}
+	if err := state.Save("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove("src"); err != nil {
+		t.Fatal(err)
+	}
+	out, err = captureStdout(t, commands.DoctorCommand)
+	var checksErr *commands.ChecksFailedError
+	if !errors.As(err, &checksErr) || checksErr.Count != 2 {
+		t.Fatal(out, err)
+	}
+	for _, line := range []string{
+		"[ok] package file is valid\n",
+		"[warn] package file: /dependencies/0/dependencies/0 at ",
+		": package \"base\" is not provided by any package\n",
+		"[warn] package file: /localDependencies/0/path at ",
+		": local package path \"src\" not found\n",
+		"[ok] all packages downloaded\n",
+		"[fail] package \"lib\" depends on \"base\", which is not provided by any package\n" +
+			"\tinstall a package that provides \"base\"\n",
+		"[fail] local package \"src\" not found at \"src\"\n" +
+			"\trestore the directory or run `oko remove src`\n",
+		"[warn] no compiler version specified\n",
+		"[ok] GitHub API reachable (60/60 requests remaining)\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("%q not in %q", line, out)
+		}
+	}
+
+	// The hint can be followed.
+	if err := commands.RemoveCommand.Call("src"); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func okoDownload(t *testing.T) {
@@ -42,6 +371,79 @@ func okoDownload(t *testing.T) {
 	}
 }
 
+func okoExec(t *testing.T) {
+	if err := commands.InitCommand.Call("--compiler=0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir("src", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.InstallCommand.Call("local", "src", "--name=src"); err != nil {
+		t.Fatal(err)
+	}
+	fakeCompiler(t, "0.0.0", `echo "$@" > moc.out; exit 3`)
+	err := commands.ExecCommand.Call("moc", "--", "--check", "main.mo")
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile("moc.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.TrimSpace(string(out)); s != "--package src src --check main.mo" {
+		t.Error(s)
+	}
+}
+
+func okoExport(t *testing.T) {
+	if err := commands.InitCommand.Call("--compiler=0.7.4"); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dep := range []config.PackageInfoRemote{
+		{Name: "base", Repository: "https://github.com/dfinity/motoko-base", Version: "moc-0.7.4"},
+		{Name: "std", Repository: "https://github.com/dfinity/motoko-base", Version: "moc-0.7.4"},
+		{Name: "testing", Repository: "https://github.com/internet-computer/testing.mo", Version: "v0.1.0", Dependencies: []string{"base"}},
+	} {
+		if err := state.AddPackage(dep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := state.Save("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := os.ReadFile("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := commands.ExportVesselCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.ExportVesselCommand.Call(); err == nil {
+		t.Fatal()
+	}
+
+	// Migrate back to Oko.
+	if err := os.Remove("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.MigrateCommand.Call("--keep"); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != string(expected) {
+		t.Error(string(raw))
+	}
+}
+
 func okoInit(t *testing.T) {
 	if err := commands.InitCommand.Call(); err != nil {
 		t.Fatal(err)
@@ -51,6 +453,322 @@ func okoInit(t *testing.T) {
 	}
 }
 
+func okoInitMetadata(t *testing.T) {
+	if err := commands.InitCommand.Call("--authors=quint, ic", "--keywords=test", "--source=lib"); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Package == nil || state.Package.Name != "commands" || len(state.Package.Authors) != 2 || state.Package.Keywords[0] != "test" {
+		t.Error(state.Package)
+	}
+	if state.Source == nil || *state.Source != "lib" {
+		t.Error(state.Source)
+	}
+}
+
+func okoBinDownload(t *testing.T) {
+	if err := commands.InitCommand.Call("--compiler=0.7.4"); err != nil {
+		t.Fatal(err)
+	}
+	server := githubtest.NewServer(t)
+	server.Install(t)
+	compiler, err := githubtest.Archive("", map[string]string{
+		"moc":    "#!/bin/sh\necho moc",
+		"mo-doc": "#!/bin/sh\necho mo-doc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	didc := []byte("#!/bin/sh\necho didc")
+	for _, goos := range []string{"linux64", "macos"} {
+		server.AddRelease("dfinity/motoko", "0.7.4", map[string][]byte{
+			fmt.Sprintf("motoko-%s-0.7.4.tar.gz", goos): compiler,
+		})
+		server.AddRelease("dfinity/candid", "2022-11-17", map[string][]byte{
+			fmt.Sprintf("didc-%s", goos):        didc,
+			fmt.Sprintf("didc-%s.sha256", goos): []byte(checksum.Sum(didc)),
+		})
+	}
+
+	// Tools get downloaded on first use, which pins their checksum.
+	if err := commands.ExecCommand.Call("mo-doc"); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := config.LoadPackageState("./oko.json"); len(state.Checksums) != 1 {
+		t.Error(state.Checksums)
+	}
+
+	if err := commands.BinCommand.Call("download", "--didc"); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.DidcVersion == nil || *state.DidcVersion != "2022-11-17" {
+		t.Error(state.DidcVersion)
+	}
+	if len(state.Checksums) != 2 {
+		t.Error(state.Checksums)
+	}
+	for _, path := range []string{filepath.Join(config.Store().CompilerDir("0.7.4"), "moc"), config.Store().DidcPath("2022-11-17")} {
+		if info, err := os.Stat(path); err != nil || info.Mode()&0o111 == 0 {
+			t.Error(path, err)
+		}
+	}
+
+	// Downloads are verified against the pinned checksums.
+	server.AddRelease("dfinity/candid", "2022-11-17", map[string][]byte{
+		"didc-linux64": []byte("tampered"),
+		"didc-macos":   []byte("tampered"),
+	})
+	if err := os.RemoveAll(config.Store().DidcPath("2022-11-17")); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.BinCommand.Call("download"); err == nil {
+		t.Error()
+	}
+}
+
+func okoInstallGitHub(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.Install(t)
+	for _, version := range []string{"v0.1.0", "v0.2.0"} {
+		server.AddArchive("org/plain", version, map[string]string{
+			"src/Plain.mo": "module {}",
+		})
+		server.AddRelease("org/plain", version, nil)
+	}
+
+	if err := commands.InstallCommand.Call("github", "org/plain", "latest", "--name=plain"); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := state.Dependencies["plain"]
+	if plain == nil || plain.Version != "v0.2.0" || plain.Repository != server.Repository("org/plain") || plain.Hash == "" {
+		t.Fatal(plain)
+	}
+
+	// Download the packages again.
+	if err := os.RemoveAll(".oko"); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.DownloadCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	// Unknown packages are not installed.
+	if err := commands.InstallCommand.Call("github", "org/unknown", "v0.1.0", "--name=unknown"); err == nil {
+		t.Error()
+	}
+}
+
+func okoUpdate(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.Install(t)
+	for _, version := range []string{"v0.1.0", "v0.2.0"} {
+		server.AddArchive("org/lib", version, map[string]string{
+			"src/Lib.mo": "module {}",
+		})
+	}
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		server.AddArchive("org/base", version, map[string]string{
+			"src/Base.mo": "module {}",
+		})
+		server.AddArchive("org/array", version, map[string]string{
+			"src/Array.mo": "module {}",
+		})
+	}
+	packageSet := func(version, baseVersion string) map[string][]byte {
+		return map[string][]byte{
+			"package-set.json": []byte(fmt.Sprintf(`[
+				{ "name": "array", "repository": "{{server}}/org/array", "version": %[2]q },
+				{ "name": "base", "repository": "{{server}}/org/base", "version": %[2]q },
+				{ "name": "lib", "repository": "{{server}}/org/lib", "version": %[1]q, "dependencies": [ "array", "base" ] }
+			]`, version, baseVersion)),
+		}
+	}
+	server.AddRelease("org/set", "latest", packageSet("v0.1.0", "v1.0.0"))
+	set := server.URL + "/org/set/releases/download/latest/package-set.json"
+
+	// Base is installed from GitHub, and shared with the package set.
+	if err := commands.InstallCommand.Call("github", "org/base", "v1.0.0", "--name=base"); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.InstallCommand.Call("set", "lib", "--set="+set); err != nil {
+		t.Fatal(err)
+	}
+	server.AddRelease("org/set", "latest", packageSet("v0.2.0", "v1.1.0"))
+	if err := commands.UpdateCommand.Call("--dry-run"); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := config.LoadPackageState("./oko.json"); state.Dependencies["lib"].Version != "v0.1.0" {
+		t.Error(state.Dependencies["lib"])
+	}
+	if err := commands.UpdateCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib := state.Dependencies["lib"]
+	if lib.Version != "v0.2.0" || lib.Hash == "" {
+		t.Error(lib)
+	}
+	if err := lib.Verify(); err != nil {
+		t.Error(err)
+	}
+	if array := state.TransitiveDependencies["array"]; array == nil || array.Version != "v1.1.0" || array.Verify() != nil {
+		t.Error(array)
+	}
+	// Packages installed from GitHub are left as is.
+	if base := state.Dependencies["base"]; base == nil || base.Version != "v1.0.0" || base.Set != "" || base.Verify() != nil {
+		t.Error(base)
+	}
+}
+
+func okoStore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(store.EnvHome, home)
+	server := githubtest.NewServer(t)
+	server.Install(t)
+	for _, org := range []string{"org", "other"} {
+		server.AddArchive(org+"/base", "v0.1.0", map[string]string{
+			"src/Base.mo": fmt.Sprintf("module { public let org = %q }", org),
+		})
+	}
+
+	// Packages with the same name from different organizations.
+	for _, args := range [][]string{
+		{"github", "org/base", "v0.1.0", "--name=base"},
+		{"github", "other/base", "v0.1.0", "--name=other"},
+	} {
+		if err := commands.InstallCommand.Call(args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, other := state.Dependencies["base"], state.Dependencies["other"]
+	if base.RelativePath() == other.RelativePath() || !strings.HasPrefix(base.RelativePath(), home) {
+		t.Fatal(base.RelativePath(), other.RelativePath())
+	}
+	if base.Hash == other.Hash {
+		t.Error(base.Hash)
+	}
+	if _, err := os.Stat(".oko/packages"); err == nil {
+		t.Error("package downloaded into `.oko`")
+	}
+}
+
+func okoInstallMops(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.Install(t)
+	server.AddArchive("org/lib", "v0.1.0", map[string]string{
+		"src/Lib.mo": "module {}",
+		"mops.toml": `[package]
+name = "lib"
+keywords = [ "motoko", "lib" ]
+
+[dependencies]
+base = "moc-0.7.4"
+`,
+	})
+	server.AddArchive("org/broken", "v0.1.0", map[string]string{
+		"src/Broken.mo": "module {}",
+		"mops.toml":     "[dependencies]\nbase = [ \"moc-0.7.4\" ]\n",
+	})
+
+	set := "--set=../vessel/testdata/package-set.json"
+	if err := commands.InstallCommand.Call("github", "org/lib", "v0.1.0", "--name=lib", set); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lib := state.Dependencies["lib"]; lib == nil || !reflect.DeepEqual(lib.Dependencies, []string{"base"}) {
+		t.Fatal(lib)
+	}
+	if base := state.TransitiveDependencies["base"]; base == nil || base.Version != "moc-0.7.4" {
+		t.Fatal(base)
+	}
+
+	// Invalid `mops.toml` files are not ignored.
+	if err := commands.InstallCommand.Call("github", "org/broken", "v0.1.0", "--name=broken", set); err == nil {
+		t.Error()
+	}
+}
+
+func okoInstallOko(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.Install(t)
+	server.AddArchive("org/lib", "v0.1.0", map[string]string{
+		"src/Lib.mo":   "module {}",
+		"util/Util.mo": "module {}",
+		"oko.json": `{
+			"version": 2,
+			"dependencies": [
+				{ "name": "base", "repository": "{{server}}/org/base", "version": "v1.0.0", "dependencies": [ "array" ] }
+			],
+			"localDependencies": [
+				{ "name": "util", "path": "util" }
+			],
+			"transitiveDependencies": [
+				{ "name": "array", "repository": "{{server}}/org/array", "version": "v0.2.0" }
+			]
+		}`,
+	})
+	server.AddArchive("org/base", "v1.0.0", map[string]string{
+		"src/Base.mo": "module {}",
+	})
+	server.AddArchive("org/array", "v0.2.0", map[string]string{
+		"src/Array.mo": "module {}",
+	})
+
+	if err := commands.InstallCommand.Call("github", "org/lib", "v0.1.0", "--name=lib"); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib := state.Dependencies["lib"]
+	if lib == nil || !reflect.DeepEqual(lib.Dependencies, []string{"base", "util"}) {
+		t.Fatal(lib)
+	}
+	base := state.TransitiveDependencies["base"]
+	if base == nil || !reflect.DeepEqual(base.Dependencies, []string{"array"}) {
+		t.Fatal(base)
+	}
+	if util := state.LocalDependencies["util"]; util == nil || util.Parent != "lib" {
+		t.Fatal(util)
+	}
+	for _, dep := range []*config.PackageInfoRemote{lib, base, state.TransitiveDependencies["array"]} {
+		if dep == nil || dep.Hash == "" {
+			t.Fatal(dep)
+		}
+		if err := dep.Verify(); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := commands.SourcesCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func okoInstallLocal(t *testing.T) {
 	args := []string{"local", "src", "--name=src"}
 	if err := commands.InstallCommand.Call(args...); err == nil {
@@ -67,6 +785,121 @@ func okoInstallLocal(t *testing.T) {
 	}
 }
 
+func okoList(t *testing.T) {
+	if err := commands.InitCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dep := config.PackageInfoRemote{
+		Name:         "lib",
+		Repository:   "https://github.com/internet-computer/lib",
+		Version:      "v0.1.0",
+		Dependencies: []string{"base"},
+	}
+	if err := state.AddPackage(dep, config.PackageInfoRemote{
+		Name:       "base",
+		Repository: "https://github.com/dfinity/motoko-base",
+		Version:    "moc-0.7.4",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Save("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dep.RelativePath(), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"README.md": "# Lib\n\nA library.\n\n## Usage",
+		"LICENSE":   "MIT License\n",
+	} {
+		if err := os.WriteFile(fmt.Sprintf("%s/%s", dep.RelativePath(), name), []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := captureStdout(t, commands.ListCommand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "NAME  VERSION    KIND        ALTS  STATUS\n" +
+		"lib   v0.1.0     direct            installed\n" +
+		"base  moc-0.7.4  transitive        missing\n"; out != expected {
+		t.Errorf("%q", out)
+	}
+	out, err = captureStdout(t, commands.ListCommand, "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var packages []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &packages); err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 2 || packages[0]["name"] != "lib" || packages[0]["installed"] != true ||
+		packages[1]["path"] != state.TransitiveDependencies["base"].RelativePath() || packages[1]["installed"] != false {
+		t.Error(packages)
+	}
+	out, err = captureStdout(t, commands.InfoCommand, "lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "name          lib\n" +
+		"kind          direct\n" +
+		"repository    https://github.com/internet-computer/lib\n" +
+		"version       v0.1.0\n" +
+		"path          " + dep.RelativePath() + "\n" +
+		"dependencies  base\n" +
+		"license       MIT\n" +
+		"\n" +
+		"A library.\n"; out != expected {
+		t.Errorf("%q", out)
+	}
+	out, err = captureStdout(t, commands.InfoCommand, "base", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info["kind"] != "transitive" || info["installed"] != false || !reflect.DeepEqual(info["dependents"], []interface{}{"lib"}) {
+		t.Error(info)
+	}
+	if _, ok := info["readme"]; ok {
+		t.Error(info)
+	}
+	if err := commands.InfoCommand.Call("unknown"); err == nil {
+		t.Fatal()
+	}
+
+	// A nested local package and a cycle.
+	state.TransitiveDependencies["base"].Dependencies = []string{"lib"}
+	if err := state.AddLocalPackage(config.PackageInfoLocal{
+		Name:   "util",
+		Path:   "util",
+		Parent: "lib",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Save("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	out, err = captureStdout(t, commands.TreeCommand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := ".\n" +
+		"└── lib v0.1.0\n" +
+		"    ├── base moc-0.7.4\n" +
+		"    │   └── lib v0.1.0 (cycle)\n" +
+		"    └── util (" + filepath.Join(dep.RelativePath(), "util") + ")\n"; out != expected {
+		t.Errorf("%q", out)
+	}
+}
+
 func okoMigrate(t *testing.T) {
 	path, err := exec.LookPath("vessel")
 	if err != nil {
@@ -87,6 +920,55 @@ func okoMigrate(t *testing.T) {
 	}
 }
 
+func okoMigrateMops(t *testing.T) {
+	if err := os.WriteFile("./mops.toml", []byte(`[toolchain]
+moc = "0.7.4"
+
+[dependencies]
+base = "moc-0.7.4"
+testing = "https://github.com/internet-computer/testing.mo#v0.1.0"
+lib = "./src"
+unknown = "0.1.0"
+`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.MigrateCommand.Call("--from=mops", "--set=../vessel/testdata/package-set.json", "--delete"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("./mops.toml"); err == nil {
+		t.Error("mops.toml not deleted")
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *state.CompilerVersion != "0.7.4" {
+		t.Error(*state.CompilerVersion)
+	}
+	if len(state.Dependencies) != 2 || len(state.LocalDependencies) != 1 || len(state.TransitiveDependencies) != 0 {
+		t.Error(state)
+	}
+	if dep := state.Dependencies["testing"]; dep == nil || dep.Version != "v0.1.0" {
+		t.Error(dep)
+	}
+}
+
+func okoMigrateSchema(t *testing.T) {
+	if err := os.WriteFile("./oko.json", []byte(`{ "dependencies": [] }`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.MigrateCommand.Call("--schema"); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := schema.Version(raw); version != schema.LatestVersion {
+		t.Error(string(raw))
+	}
+}
+
 func okoRemoveLocal(t *testing.T) {
 	args := []string{"src"}
 	if err := commands.RemoveCommand.Call(args...); err != nil {
@@ -97,6 +979,211 @@ func okoRemoveLocal(t *testing.T) {
 	}
 }
 
+func okoRun(t *testing.T) {
+	if err := commands.InitCommand.Call("--compiler=0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	fakeCompiler(t, "0.0.0", "")
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Scripts["moc"] = `echo "$(command -v moc) $OKO_MOC" > moc.out; echo`
+	if err := state.Save("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.RunCommand.Call("unknown"); err == nil {
+		t.Fatal()
+	}
+	if err := commands.RunCommand.Call("moc", "--", "x"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile("moc.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moc, _ := filepath.Abs(".oko/bin/0.0.0/moc")
+	if s := strings.TrimSpace(string(out)); s != fmt.Sprintf("%s %s", moc, moc) {
+		t.Error(s)
+	}
+}
+
+func okoSubdirectory(t *testing.T) {
+	if err := commands.InitCommand.Call("--compiler=0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll("src/lib", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeCompiler(t, "0.0.0", fmt.Sprintf(`echo "$@" > %q`, filepath.Join(wd, "moc.out")))
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Targets["main"] = config.BuildTarget{Entry: "src/main.mo", Output: "main.wasm"}
+	state.Scripts["pwd"] = "pwd > pwd.out"
+	if err := state.Save("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove("pwd.out")
+	defer func() {
+		_ = os.Chdir(wd)
+		config.SetManifest("")
+	}()
+	if err := commands.ApplyGlobalOptions(map[string]string{"directory": "src"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.InstallCommand.Call("local", "lib", "--name=lib"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Targets and scripts are relative to the root directory.
+	if err := commands.BuildCommand.Call("main"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := os.ReadFile(filepath.Join(wd, "moc.out")); err != nil || !strings.HasSuffix(strings.TrimSpace(string(out)), "-o ../main.wasm ../src/main.mo") {
+		t.Error(string(out), err)
+	}
+	if err := commands.RunCommand.Call("pwd"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := os.ReadFile(filepath.Join(wd, "pwd.out")); err != nil || strings.TrimSpace(string(out)) != wd {
+		t.Error(string(out), err)
+	}
+
+	if err := commands.ExportVesselCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Migrate next to an explicit package file.
+	if err := os.WriteFile("lib/mops.toml", []byte("[toolchain]\nmoc = \"0.7.4\"\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.ApplyGlobalOptions(map[string]string{"manifest": "lib/oko.json"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.MigrateCommand.Call("--from=mops", "--keep"); err != nil {
+		t.Fatal(err)
+	}
+	config.SetManifest("")
+
+	if err := commands.ApplyGlobalOptions(map[string]string{"directory": "unknown"}); err == nil {
+		t.Fatal()
+	}
+	if err := os.Chdir(wd); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep, ok := state.LocalDependencies["lib"]; !ok || dep.Path != "src/lib" {
+		t.Error(state.LocalDependencies)
+	}
+	for path, exists := range map[string]bool{
+		"vessel.dhall":          true,
+		"package-set.dhall":     true,
+		"src/vessel.dhall":      false,
+		"src/package-set.dhall": false,
+		"src/lib/oko.json":      true,
+		"src/oko.json":          false,
+	} {
+		if _, err := os.Stat(path); (err == nil) != exists {
+			t.Error(path, err)
+		}
+	}
+}
+
+func okoTest(t *testing.T) {
+	if err := commands.InitCommand.Call("--compiler=0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	// The last argument is the test file, fails if it contains "fail".
+	fakeCompiler(t, "0.0.0", `for f; do :; done; ! grep -q fail "$f"`)
+	if err := os.Mkdir("test", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"test/a.test.mo": "pass",
+		"test/b.test.mo": "fail",
+		"test/c.mo":      "fail",
+	} {
+		if err := os.WriteFile(name, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := commands.TestCommand.Call("--parallel=2", "--junit=junit.xml"); err == nil {
+		t.Fatal()
+	}
+	raw, err := os.ReadFile("junit.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `tests="2" failures="1"`) {
+		t.Error(string(raw))
+	}
+	if err := commands.TestCommand.Call("--filter=a.test"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func okoVerify(t *testing.T) {
+	if err := commands.InitCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState("./oko.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dep := config.PackageInfoRemote{
+		Name:       "lib",
+		Repository: "https://github.com/internet-computer/lib",
+		Version:    "v0.1.0",
+	}
+	if err := state.AddPackage(dep); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Save("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	// Not downloaded.
+	if err := commands.VerifyCommand.Call(); err == nil {
+		t.Fatal()
+	}
+
+	src := fmt.Sprintf("%s/src", dep.RelativePath())
+	if err := os.MkdirAll(src, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fmt.Sprintf("%s/lib.mo", src), []byte("module {}"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := checksum.Dir(dep.RelativePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Dependencies["lib"].Hash = hash
+	if err := state.Save("./oko.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.VerifyCommand.Call(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Modified.
+	if err := os.WriteFile(fmt.Sprintf("%s/lib.mo", src), []byte("module { }"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := commands.VerifyCommand.Call(); err == nil {
+		t.Fatal()
+	}
+}
+
 type Test struct {
 	Name string
 	T    func(*testing.T)
@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/config/schema"
+	"github.com/internet-computer/oko/github"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+var DoctorCommand = cmd.Command{
+	Name:    "doctor",
+	Summary: "diagnose common problems",
+	Description: "Runs a set of checks on the environment and the Oko package file and prints the results.\n\n" +
+		"Checks the package file, the downloaded packages, the dependencies, the toolchain and the GitHub API. " +
+		"Problems in the package file that do not prevent it from being used are reported with their location.",
+	Method: func(_ []string, _ map[string]string) error {
+		var d doctor
+		d.run()
+		if d.failures != 0 {
+			return NewDoctorError(NewChecksFailedError(d.failures))
+		}
+		return nil
+	},
+}
+
+type ChecksFailedError struct {
+	Count int
+}
+
+func NewChecksFailedError(count int) *ChecksFailedError {
+	return &ChecksFailedError{
+		Count: count,
+	}
+}
+
+func (e ChecksFailedError) Error() string {
+	return fmt.Sprintf("%d check(s) failed", e.Count)
+}
+
+type DoctorError struct {
+	Err error
+}
+
+func NewDoctorError(err error) *DoctorError {
+	return &DoctorError{
+		Err: err,
+	}
+}
+
+func (e DoctorError) Error() string {
+	return fmt.Sprintf("doctor error: %s", e.Err)
+}
+
+func (e DoctorError) Unwrap() error {
+	return e.Err
+}
+
+// doctor runs the checks and keeps track of the failures.
+type doctor struct {
+	failures int
+}
+
+func (d *doctor) checkDependencies(state *config.PackageState) {
+	unresolved := state.UnresolvedDependencies()
+	for _, err := range unresolved {
+		d.fail(err.Error(), fmt.Sprintf("install a package that provides %q", err.DependencyName))
+	}
+	var missing int
+	for _, dep := range sortedLocalPackages(state.LocalDependencies) {
+		if dep.Parent != "" && state.GetByName(dep.Parent) == nil {
+			missing++
+			d.fail(
+				fmt.Sprintf("parent package %q of local package %q not found", dep.Parent, dep.Name),
+				fmt.Sprintf("run `oko remove %s`", dep.Name),
+			)
+			continue
+		}
+		if _, err := os.Stat(state.LocalPath(*dep)); err != nil {
+			missing++
+			hint := fmt.Sprintf("restore the directory or run `oko remove %s`", dep.Name)
+			if dep.Parent != "" {
+				hint = "run `oko download`"
+			}
+			d.fail(fmt.Sprintf("local package %q not found at %q", dep.Name, state.LocalPath(*dep)), hint)
+		}
+	}
+	if len(unresolved) == 0 && missing == 0 {
+		d.ok("all dependencies resolved")
+	}
+}
+
+func (d *doctor) checkGitHub() {
+	limit, err := github.GetRateLimit()
+	if err != nil {
+		d.warn(fmt.Sprintf("GitHub API not reachable: %s", err), "check your network connection")
+		return
+	}
+	if limit.Remaining == 0 {
+		d.fail(
+			fmt.Sprintf("GitHub API rate limit exceeded (%d requests)", limit.Limit),
+			fmt.Sprintf("wait until %s", limit.ResetTime().Format(time.Kitchen)),
+		)
+		return
+	}
+	d.ok(fmt.Sprintf("GitHub API reachable (%d/%d requests remaining)", limit.Remaining, limit.Limit))
+}
+
+func (d *doctor) checkPackages(state *config.PackageState) {
+	var broken int
+	for _, dep := range append(sortedPackages(state.Dependencies), sortedPackages(state.TransitiveDependencies)...) {
+		if err := dep.Verify(); err != nil {
+			broken++
+			d.fail(err.Error(), "run `oko download` or `oko verify --fix`")
+		}
+	}
+	if broken == 0 {
+		d.ok("all packages downloaded")
+	}
+
+	keep, err := usedStorePaths()
+	if err != nil {
+		d.fail(err.Error(), "")
+		return
+	}
+	stale, err := config.Store().Stale(keep)
+	if err != nil {
+		d.fail(err.Error(), "")
+		return
+	}
+	if len(stale) != 0 {
+		d.warn(fmt.Sprintf("unused entries in the store: %s", strings.Join(stale, ", ")), "run `oko clean`")
+	}
+}
+
+func (d *doctor) checkToolchain(state *config.PackageState) {
+	if state.CompilerVersion == nil {
+		d.warn("no compiler version specified", "run `oko init --compiler <version>`")
+	} else {
+		moc := filepath.Join(compilerDir(*state.CompilerVersion), "moc")
+		if _, err := os.Stat(moc); err != nil {
+			d.fail(fmt.Sprintf("compiler %s not downloaded", *state.CompilerVersion), "run `oko bin download`")
+		} else if out, err := exec.Command(moc, "--version").Output(); err != nil {
+			d.fail(fmt.Sprintf("compiler %s can not be executed: %s", *state.CompilerVersion, err), "run `oko bin download`")
+		} else {
+			d.ok(strings.TrimSpace(string(out)))
+		}
+	}
+	if state.DidcVersion != nil {
+		if _, err := os.Stat(didcPath(*state.DidcVersion)); err != nil {
+			d.fail(fmt.Sprintf("didc %s not downloaded", *state.DidcVersion), "run `oko bin download`")
+		} else {
+			d.ok(fmt.Sprintf("didc %s downloaded", *state.DidcVersion))
+		}
+	}
+}
+
+func (d *doctor) fail(msg, hint string) {
+	d.failures++
+	d.print("fail", msg, hint)
+}
+
+func (d doctor) ok(msg string) {
+	d.print("ok", msg, "")
+}
+
+func (d doctor) print(level, msg, hint string) {
+	fmt.Printf("[%s] %s\n", level, msg)
+	if hint != "" {
+		fmt.Printf("\t%s\n", hint)
+	}
+}
+
+// run runs all checks.
+func (d *doctor) run() {
+	defer d.checkGitHub()
+
+	if _, err := os.Stat(config.ManifestPath()); err != nil {
+		d.fail(fmt.Sprintf("package file not readable: %s", err), "run `oko init` or `oko migrate`")
+		return
+	}
+	state, err := config.LoadPackageState(config.ManifestPath())
+	if err != nil {
+		var validationErr *schema.ValidationError
+		if !errors.As(err, &validationErr) {
+			d.fail(err.Error(), "")
+			return
+		}
+		for _, problem := range validationErr.Problems {
+			d.fail(fmt.Sprintf("package file is invalid: %s", problem), "fix the package file")
+		}
+		return
+	}
+	d.ok("package file is valid")
+	for _, warning := range state.Warnings {
+		d.warn(fmt.Sprintf("package file: %s", warning), "")
+	}
+	d.checkPackages(state)
+	d.checkDependencies(state)
+	d.checkToolchain(state)
+}
+
+func (d doctor) warn(msg, hint string) {
+	d.print("warn", msg, hint)
+}
@@ -11,15 +11,25 @@ var DownloadCommand = cmd.Command{
 	Name:        "download",
 	Aliases:     []string{"d"},
 	Summary:     "download packages",
-	Description: `Downloads all packages specified in the Oko package file.`,
+	Description: `Downloads all packages specified in the Oko package file and records the hashes of their contents.`,
 	Method: func(_ []string, _ map[string]string) error {
-		state, err := config.LoadPackageState("./oko.json")
+		l, err := config.LockManifest(config.ManifestPath())
+		if err != nil {
+			return NewDownloadError(err)
+		}
+		defer l.Unlock()
+
+		state, err := config.LoadPackageState(config.ManifestPath())
 		if err != nil {
 			return NewDownloadError(err)
 		}
 		if err := state.Download(); err != nil {
 			return NewDownloadError(err)
 		}
+		// Record the hashes of the downloaded packages.
+		if err := state.Save(config.ManifestPath()); err != nil {
+			return NewDownloadError(err)
+		}
 		return nil
 	},
 }
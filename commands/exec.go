@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+var ExecCommand = cmd.Command{
+	Name:    "exec",
+	Aliases: []string{"x"},
+	Summary: "run toolchain binaries",
+	Description: "Runs a tool (e.g. `moc`, `mo-doc` or `didc`) with the versions specified in the Oko package file.\n\n" +
+		"Tools get downloaded if they are not present yet. " +
+		"The package sources are passed to `moc` automatically. " +
+		"Exits with the exit code of the tool.",
+	Args:     []string{"tool"},
+	Variadic: true,
+	Method: func(args []string, _ map[string]string) error {
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewExecError(err)
+		}
+
+		var (
+			tool      = args[0]
+			path      string
+			arguments = args[1:]
+		)
+		switch tool {
+		case "didc":
+			path, err = didcTool(state)
+		case "moc":
+			path, err = compilerTool(state, tool)
+			arguments = append(packageSources(state), arguments...)
+		default:
+			path, err = compilerTool(state, tool)
+		}
+		if err != nil {
+			return NewExecError(err)
+		}
+
+		env, err := toolchainEnv(state)
+		if err != nil {
+			return NewExecError(err)
+		}
+		run := exec.Command(path, arguments...)
+		run.Env = env
+		run.Stdin = os.Stdin
+		run.Stdout = os.Stdout
+		run.Stderr = os.Stderr
+		if err := run.Run(); err != nil {
+			return NewExecError(err)
+		}
+		return nil
+	},
+}
+
+type ExecError struct {
+	Err error
+}
+
+func NewExecError(err error) *ExecError {
+	return &ExecError{
+		Err: err,
+	}
+}
+
+func (e ExecError) Error() string {
+	return fmt.Sprintf("exec error: %s", e.Err)
+}
+
+func (e ExecError) Unwrap() error {
+	return e.Err
+}
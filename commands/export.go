@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+	"github.com/internet-computer/oko/vessel"
+)
+
+var ExportCommand = cmd.Command{
+	Name:        "export",
+	Summary:     "export packages",
+	Description: `Allows you to export the Oko package file to other formats.`,
+	Commands: []cmd.Command{
+		ExportVesselCommand,
+	},
+}
+
+var ExportVesselCommand = cmd.Command{
+	Name:    "vessel",
+	Summary: "export to Vessel",
+	Description: "Writes a `vessel.dhall` and `package-set.dhall` file based on the Oko package file.\n\n" +
+		"Alternative names result in separate packages in the package set, which `oko migrate` merges again. " +
+		"Direct dependencies that other packages depend on are only added to the package set. " +
+		"Local packages are not supported by Vessel and are skipped.",
+	Options: []cmd.Option{
+		{
+			Name:     "force",
+			Summary:  "overwrite existing files",
+			HasValue: false,
+		},
+	},
+	Method: func(_ []string, options map[string]string) error {
+		if _, ok := options["force"]; !ok {
+			for _, path := range []string{config.Path("vessel.dhall"), config.Path("package-set.dhall")} {
+				if _, err := os.Stat(path); err == nil {
+					return NewExportError(NewOptionsError(fmt.Sprintf("%q already exists, use `--force` to overwrite it", path)))
+				}
+			}
+		}
+
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewExportError(err)
+		}
+		for _, dep := range state.LocalDependencies {
+			fmt.Printf("skipping local package %q\n", dep.Name)
+		}
+
+		if err := vessel.NewManifestFromState(*state).SaveDhall(config.Path("vessel.dhall")); err != nil {
+			return NewExportError(err)
+		}
+		if err := vessel.NewPackageSetFromState(*state).SaveDhall(config.Path("package-set.dhall")); err != nil {
+			return NewExportError(err)
+		}
+		return nil
+	},
+}
+
+type ExportError struct {
+	Err error
+}
+
+func NewExportError(err error) *ExportError {
+	return &ExportError{
+		Err: err,
+	}
+}
+
+func (e ExportError) Error() string {
+	return fmt.Sprintf("export error: %s", e.Err)
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+// GlobalOptions are the options that apply to all commands.
+var GlobalOptions = []cmd.Option{
+	{
+		Name:     "manifest",
+		Summary:  "path to the Oko package file",
+		HasValue: true,
+	},
+	{
+		Name:     "store",
+		Summary:  "root directory of the package store",
+		HasValue: true,
+	},
+	{
+		Name:      "directory",
+		Shorthand: "C",
+		Summary:   "run as if started in the given directory",
+		HasValue:  true,
+	},
+}
+
+// ApplyGlobalOptions applies the given global options. The directory is
+// changed first, so the path to the package file is relative to it.
+func ApplyGlobalOptions(options map[string]string) error {
+	if dir, ok := options["directory"]; ok {
+		if err := os.Chdir(dir); err != nil {
+			return NewPathNotFoundError(dir)
+		}
+	}
+	if path, ok := options["manifest"]; ok {
+		config.SetManifest(path)
+	}
+	if root, ok := options["store"]; ok {
+		config.SetStore(root)
+	}
+	return nil
+}
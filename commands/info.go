@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+var InfoCommand = cmd.Command{
+	Name:        "info",
+	Summary:     "show package details",
+	Description: `Shows the details of the package with the given name, including its dependents, dependencies, license and README.`,
+	Args:        []string{"name"},
+	Options: []cmd.Option{
+		{
+			Name:     "json",
+			HasValue: false,
+		},
+	},
+	Method: func(args []string, options map[string]string) error {
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewInfoError(err)
+		}
+
+		var info packageInfo
+		if dep := state.GetByName(args[0]); dep != nil {
+			kind := "direct"
+			if _, ok := state.Dependencies[dep.Name]; !ok {
+				kind = "transitive"
+			}
+			info = packageInfo{
+				listEntry:    newListEntry(*dep, kind),
+				Dependents:   state.GetDependents(*dep),
+				Dependencies: dep.Dependencies,
+			}
+			info.License = readLicense(dep.RelativePath())
+			info.Readme = readReadme(dep.RelativePath())
+		} else if dep, ok := state.LocalDependencies[args[0]]; ok {
+			_, err := os.Stat(state.LocalPath(*dep))
+			info = packageInfo{
+				listEntry: listEntry{
+					Name:      dep.Name,
+					Kind:      "local",
+					Path:      state.LocalPath(*dep),
+					Installed: err == nil,
+				},
+			}
+		} else {
+			return NewInfoError(config.NewPackageNotFoundError(args[0]))
+		}
+
+		if _, ok := options["json"]; ok {
+			return printJSON(info)
+		}
+		table := [][]string{
+			{"name", info.Name},
+			{"kind", info.Kind},
+		}
+		if len(info.AlternativeNames) != 0 {
+			table = append(table, []string{"alts", strings.Join(info.AlternativeNames, ", ")})
+		}
+		if info.Repository != "" {
+			table = append(table, []string{"repository", info.Repository})
+			table = append(table, []string{"version", info.Version})
+		}
+		table = append(table, []string{"path", info.Path})
+		if !info.Installed {
+			table = append(table, []string{"status", "missing"})
+		}
+		if len(info.Dependents) != 0 {
+			table = append(table, []string{"dependents", strings.Join(info.Dependents, ", ")})
+		}
+		if len(info.Dependencies) != 0 {
+			table = append(table, []string{"dependencies", strings.Join(info.Dependencies, ", ")})
+		}
+		if info.License != "" {
+			table = append(table, []string{"license", info.License})
+		}
+		fmt.Println(cmd.FormatTable(table, "  ", "\n", ""))
+		if info.Readme != "" {
+			fmt.Printf("\n%s\n", info.Readme)
+		}
+		return nil
+	},
+}
+
+// readLicense returns the license of the package in the given directory.
+// Returns the first line of the license file if the license is not recognized.
+func readLicense(dir string) string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "LICENSE*"))
+	if len(matches) == 0 {
+		return ""
+	}
+	raw, err := os.ReadFile(matches[0])
+	if err != nil {
+		return ""
+	}
+	license := string(raw)
+	for _, l := range []struct {
+		id, text string
+	}{
+		{"MIT", "MIT License"},
+		{"Apache-2.0", "Apache License"},
+		{"GPL-3.0", "GNU GENERAL PUBLIC LICENSE"},
+		{"BSD-3-Clause", "Redistribution and use in source and binary forms"},
+		{"Unlicense", "This is free and unencumbered software"},
+	} {
+		if strings.Contains(license, l.text) {
+			return l.id
+		}
+	}
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(license), "\n", 2)[0])
+}
+
+// readReadme returns the first paragraph of the README of the package in the
+// given directory. Headings and badges are skipped.
+func readReadme(dir string) string {
+	file, err := os.Open(filepath.Join(dir, "README.md"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	var paragraph []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			if len(paragraph) != 0 {
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "![") {
+			if len(paragraph) != 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	return strings.Join(paragraph, "\n")
+}
+
+type InfoError struct {
+	Err error
+}
+
+func NewInfoError(err error) *InfoError {
+	return &InfoError{
+		Err: err,
+	}
+}
+
+func (e InfoError) Error() string {
+	return fmt.Sprintf("info error: %s", e.Err)
+}
+
+type packageInfo struct {
+	listEntry
+	Dependents   []string `json:"dependents,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	License      string   `json:"license,omitempty"`
+	Readme       string   `json:"readme,omitempty"`
+}
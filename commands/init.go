@@ -2,37 +2,148 @@ package commands
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/internet-computer/oko/config"
 	"github.com/internet-computer/oko/internal/cmd"
 )
 
 var InitCommand = cmd.Command{
-	Name:        "init",
-	Summary:     "initialize Oko",
-	Description: `Initializes the Oko package file.`,
+	Name:    "init",
+	Summary: "initialize Oko",
+	Description: "Initializes the Oko package file.\n\n" +
+		"If started in a terminal, the package metadata that is not passed as an option is prompted for. " +
+		"Authors and keywords are comma separated.",
 	Options: []cmd.Option{
 		{
 			Name:     "compiler",
 			Summary:  "compiler version",
 			HasValue: true,
 		},
+		{
+			Name:     "didc",
+			Summary:  "didc version",
+			HasValue: true,
+		},
+		{
+			Name:     "name",
+			Summary:  "package name",
+			HasValue: true,
+		},
+		{
+			Name:     "version",
+			Summary:  "package version",
+			HasValue: true,
+		},
+		{
+			Name:     "description",
+			Summary:  "package description",
+			HasValue: true,
+		},
+		{
+			Name:     "license",
+			Summary:  "package license",
+			HasValue: true,
+		},
+		{
+			Name:     "authors",
+			Summary:  "package authors",
+			HasValue: true,
+		},
+		{
+			Name:     "repository",
+			Summary:  "package repository",
+			HasValue: true,
+		},
+		{
+			Name:     "keywords",
+			Summary:  "package keywords",
+			HasValue: true,
+		},
+		{
+			Name:     "source",
+			Summary:  "source directory",
+			HasValue: true,
+		},
+		{
+			Name:      "yes",
+			Shorthand: "y",
+		},
 	},
 	Method: func(_ []string, options map[string]string) error {
-		if _, err := config.LoadPackageState("./oko.json"); err == nil {
+		l, err := config.LockManifest(config.NewManifestPath())
+		if err != nil {
+			return NewInitError(err)
+		}
+		defer l.Unlock()
+
+		if _, err := config.LoadPackageState(config.NewManifestPath()); err == nil {
 			return NewInitError(err)
 		}
 		state := config.EmptyState()
 		if v, ok := options["compiler"]; ok {
 			state.CompilerVersion = &v
 		}
-		if err := state.Save("./oko.json"); err != nil {
+		if v, ok := options["didc"]; ok {
+			state.DidcVersion = &v
+		}
+
+		_, yes := options["yes"]
+		ask := !yes && cmd.IsTerminal()
+		option := func(name, question, def string) string {
+			if v, ok := options[name]; ok {
+				return v
+			}
+			if ask {
+				return cmd.AskWithDefault(question, def)
+			}
+			return ""
+		}
+		dir, err := filepath.Abs(filepath.Dir(config.NewManifestPath()))
+		if err != nil {
+			return NewInitError(err)
+		}
+		metadata := config.PackageMetadata{
+			Name:        option("name", "Package name", filepath.Base(dir)),
+			Version:     option("version", "Version", "0.1.0"),
+			Description: option("description", "Description", ""),
+			License:     option("license", "License", ""),
+			Authors:     splitList(option("authors", "Authors", "")),
+			Repository:  option("repository", "Repository", ""),
+			Keywords:    splitList(option("keywords", "Keywords", "")),
+		}
+		for _, name := range []string{"version", "description", "license", "authors", "repository", "keywords"} {
+			// The name is required if any metadata is passed.
+			if _, ok := options[name]; ok && metadata.Name == "" {
+				metadata.Name = filepath.Base(dir)
+			}
+		}
+		if metadata.Name != "" {
+			state.Package = &metadata
+		}
+		if source := option("source", "Source directory", config.DefaultSource); source != "" && source != config.DefaultSource {
+			state.Source = &source
+		}
+
+		if err := state.Save(config.NewManifestPath()); err != nil {
 			return NewInitError(err)
 		}
 		return nil
 	},
 }
 
+// splitList splits the given comma separated list.
+func splitList(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 type InitError struct {
 	Err error
 }
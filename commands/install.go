@@ -1,16 +1,15 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/internet-computer/oko/config"
 	"github.com/internet-computer/oko/github"
 	"github.com/internet-computer/oko/internal/cmd"
+	"github.com/internet-computer/oko/mops"
 	"github.com/internet-computer/oko/vessel"
 )
 
@@ -22,6 +21,7 @@ var InstallCommand = cmd.Command{
 	Commands: []cmd.Command{
 		InstallGitHubCommand,
 		InstallLocalCommand,
+		InstallSetCommand,
 	},
 }
 
@@ -31,7 +31,8 @@ var InstallGitHubCommand = cmd.Command{
 	Summary: "install GitHub hosted packages",
 	Description: "Allows you to install packages from GitHub.\n\n" +
 		"Expects `{org}/{repo}`, i.e. if you want to install the package at https://github.com/internet-computer/testing.mo you will have to pass `internet-computer/testing.mo` to the first argument.\n\n" +
-		"Instead of specifying a specific version, `latest` can be used.",
+		"Instead of specifying a specific version, `latest` can be used.\n\n" +
+		"The dependencies of the package are read from its `vessel.dhall`, `oko.json` or `mops.toml` file.",
 	Args: []string{"url", "version"},
 	Options: []cmd.Option{
 		{
@@ -39,31 +40,25 @@ var InstallGitHubCommand = cmd.Command{
 			Summary:  "package name",
 			HasValue: true,
 		},
+		{
+			Name:     "set",
+			Summary:  "package set location for mops packages",
+			HasValue: true,
+		},
 	},
 	Method: func(args []string, options map[string]string) error {
 		url := args[0]
 		version := args[1]
 		if version == "latest" {
-			resp, err := http.Get(fmt.Sprintf("https://api.github.com/repos/%s/releases", url))
-			if err != nil {
-				return NewInstallError(err)
-			}
-			data, err := io.ReadAll(resp.Body)
+			release, err := github.LatestRelease(url)
 			if err != nil {
 				return NewInstallError(err)
 			}
-			var releases []github.Release
-			if err := json.Unmarshal(data, &releases); err != nil {
-				return NewInstallError(err)
-			}
-			if len(releases) == 0 {
-				return NewInstallError(github.NewReleasesNotFoundErrors(url))
-			}
-			version = releases[0].TagName
+			version = release.TagName
 		}
 
 		info := config.PackageInfoRemote{
-			Repository: fmt.Sprintf("https://github.com/%s", url),
+			Repository: fmt.Sprintf("%s/%s", github.URL, url),
 			Version:    version,
 		}
 
@@ -80,7 +75,13 @@ var InstallGitHubCommand = cmd.Command{
 			info.Name = name
 		}
 
-		state, err := config.LoadPackageState("./oko.json")
+		l, err := config.LockManifest(config.ManifestPath())
+		if err != nil {
+			return NewInstallError(err)
+		}
+		defer l.Unlock()
+
+		state, err := config.LoadPackageState(config.ManifestPath())
 		if err != nil {
 			return NewInstallError(err)
 		}
@@ -90,14 +91,14 @@ var InstallGitHubCommand = cmd.Command{
 		}
 
 		// VESSEL
-		if raw, err := os.ReadFile(fmt.Sprintf("%s/vessel.dhall", info.RelativePath())); err == nil {
+		if raw, err := os.ReadFile(filepath.Join(info.RelativePath(), "vessel.dhall")); err == nil {
 			manifest, err := vessel.NewManifest(raw)
 			if err != nil {
 				return NewInstallError(err)
 			}
 			info.Dependencies = manifest.Dependencies
 			if len(manifest.Dependencies) != 0 {
-				packageSet, err := vessel.LoadPackageSet(fmt.Sprintf("%s/package-set.dhall", info.RelativePath()))
+				packageSet, err := vessel.LoadPackageSet(filepath.Join(info.RelativePath(), "package-set.dhall"))
 				if err != nil {
 					return NewInstallError(err)
 				}
@@ -113,35 +114,71 @@ var InstallGitHubCommand = cmd.Command{
 					return nil
 				}
 			}
-			if err := state.Save("./oko.json"); err != nil {
+			if err := state.Save(config.ManifestPath()); err != nil {
 				return NewInstallError(err)
 			}
 			return nil
 		}
 
 		// OKO
-		if raw, err := os.ReadFile(fmt.Sprintf("%s/oko.json", info.RelativePath())); err == nil {
+		if raw, err := os.ReadFile(filepath.Join(info.RelativePath(), "oko.json")); err == nil {
 			pkg, err := config.NewPackageConfig(raw)
 			if err != nil {
 				return NewInstallError(err)
 			}
-			if err := state.LoadState(config.NewPackageState(pkg)); err != nil {
+			other := config.NewPackageState(pkg)
+			if err := state.LoadState(other, &info); err != nil {
+				return NewInstallError(err)
+			}
+			var dependencies []config.PackageInfoRemote
+			for _, dep := range other.Dependencies {
+				dependencies = append(dependencies, *dep)
+			}
+			for _, dep := range other.TransitiveDependencies {
+				dependencies = append(dependencies, *dep)
+			}
+			if err := downloadPackages(state, dependencies...); err != nil {
 				return NewInstallError(err)
 			}
-			if err := state.Save("./oko.json"); err != nil {
+			if err := state.Save(config.ManifestPath()); err != nil {
 				return NewInstallError(err)
 			}
 			return nil
 		}
 
-		// No `vessel.dhall` or `oko.json`.
-		if _, err := os.Stat(fmt.Sprintf("%s/src", info.RelativePath())); err != nil {
+		// MOPS
+		if raw, err := os.ReadFile(filepath.Join(info.RelativePath(), "mops.toml")); err == nil {
+			manifest, err := mops.NewManifest(raw)
+			if err != nil {
+				return NewInstallError(err)
+			}
+			packages, err := resolveMops(manifest, packageSetLocation(options))
+			if err != nil {
+				return NewInstallError(err)
+			}
+			for _, dep := range packages.LocalDependencies {
+				fmt.Printf("skipping local package %q of %q\n", dep.Name, info.Name)
+			}
+			info.Dependencies = packages.names()
+			if err := state.AddPackage(info, append(packages.Dependencies, packages.TransitiveDependencies...)...); err != nil {
+				return NewInstallError(err)
+			}
+			if err := state.Save(config.ManifestPath()); err != nil {
+				return NewInstallError(err)
+			}
+			return nil
+		} else if !os.IsNotExist(err) {
+			return NewInstallError(err)
+		}
+
+		// No `vessel.dhall`, `oko.json` or `mops.toml`.
+		if _, err := os.Stat(info.SourcePath()); err != nil {
 			return NewInstallError(err)
 		}
 		if err := state.AddPackage(info); err != nil {
 			return NewInstallError(err)
 		}
-		if err := state.Save("./oko.json"); err != nil {
+		if err := state.Save(config.ManifestPath()); err != nil {
 			return NewInstallError(err)
 		}
 		return nil
@@ -166,8 +203,19 @@ var InstallLocalCommand = cmd.Command{
 		if _, err := os.Stat(path); err != nil {
 			return NewInstallError(err)
 		}
+		// The path in the package file is relative to the root directory.
+		rel := path
+		if !filepath.IsAbs(path) {
+			root, rootErr := filepath.Abs(config.Root())
+			abs, absErr := filepath.Abs(path)
+			if rootErr == nil && absErr == nil {
+				if p, err := filepath.Rel(root, abs); err == nil {
+					rel = p
+				}
+			}
+		}
 		info := config.PackageInfoLocal{
-			Path: path,
+			Path: filepath.ToSlash(rel),
 		}
 
 		name, ok := options["name"]
@@ -183,20 +231,111 @@ var InstallLocalCommand = cmd.Command{
 			info.Name = name
 		}
 
-		state, err := config.LoadPackageState("./oko.json")
+		l, err := config.LockManifest(config.ManifestPath())
+		if err != nil {
+			return NewInstallError(err)
+		}
+		defer l.Unlock()
+
+		state, err := config.LoadPackageState(config.ManifestPath())
 		if err != nil {
 			return NewInstallError(err)
 		}
 		if err := state.AddLocalPackage(info); err != nil {
 			return NewInstallError(err)
 		}
-		if err := state.Save("./oko.json"); err != nil {
+		if err := state.Save(config.ManifestPath()); err != nil {
 			return NewInstallError(err)
 		}
 		return nil
 	},
 }
 
+var InstallSetCommand = cmd.Command{
+	Name:    "set",
+	Aliases: []string{"s"},
+	Summary: "install packages from a package set",
+	Description: "Allows you to install packages by name from a package set, together with all their dependencies.\n\n" +
+		"The package set is either specified with `--set`, the `packageSet` field of the Oko package file, or the default Vessel package set. " +
+		"The package set gets recorded, so that `oko update` can follow it.",
+	Args: []string{"name"},
+	Options: []cmd.Option{
+		{
+			Name:     "set",
+			Summary:  "package set location",
+			HasValue: true,
+		},
+	},
+	Method: func(args []string, options map[string]string) error {
+		l, err := config.LockManifest(config.ManifestPath())
+		if err != nil {
+			return NewInstallError(err)
+		}
+		defer l.Unlock()
+
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewInstallError(err)
+		}
+
+		info, dependencies, err := packageSetPackages(packageSetLocation(options), args[0])
+		if err != nil {
+			return NewInstallError(err)
+		}
+		if err := state.AddPackage(info, dependencies...); err != nil {
+			return NewInstallError(err)
+		}
+		if err := downloadPackages(state, append(dependencies, info)...); err != nil {
+			return NewInstallError(err)
+		}
+		if err := state.Save(config.ManifestPath()); err != nil {
+			return NewInstallError(err)
+		}
+		return nil
+	},
+}
+
+// downloadPackages downloads the packages in the state that match the given
+// packages, so that their hashes get recorded in the state.
+func downloadPackages(state *config.PackageState, packages ...config.PackageInfoRemote) error {
+	for _, pkg := range packages {
+		dep := state.GetByName(pkg.Name)
+		if dep == nil {
+			return config.NewPackageNotFoundError(pkg.Name)
+		}
+		if err := dep.Download(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packageSetPackages looks up the package with the given name in the package
+// set at the given location. Returns the package and its dependency closure.
+func packageSetPackages(location, name string) (config.PackageInfoRemote, []config.PackageInfoRemote, error) {
+	set, err := vessel.LoadIndex(location)
+	if err != nil {
+		return config.PackageInfoRemote{}, nil, err
+	}
+	packages, err := set.Filter([]string{name})
+	if err != nil {
+		return config.PackageInfoRemote{}, nil, err
+	}
+	var (
+		info         config.PackageInfoRemote
+		dependencies []config.PackageInfoRemote
+	)
+	for _, pkg := range packages.Oko() {
+		pkg.Set = location
+		if pkg.Name == name {
+			info = pkg
+			continue
+		}
+		dependencies = append(dependencies, pkg)
+	}
+	return info, dependencies, nil
+}
+
 type InstallError struct {
 	Err error
 }
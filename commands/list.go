@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+var ListCommand = cmd.Command{
+	Name:        "list",
+	Aliases:     []string{"ls"},
+	Summary:     "list packages",
+	Description: `Lists all direct, transitive and local packages with their versions and install status.`,
+	Options: []cmd.Option{
+		{
+			Name:     "json",
+			HasValue: false,
+		},
+	},
+	Method: func(_ []string, options map[string]string) error {
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewListError(err)
+		}
+
+		var packages []listEntry
+		for _, dep := range sortedPackages(state.Dependencies) {
+			packages = append(packages, newListEntry(*dep, "direct"))
+		}
+		for _, dep := range sortedPackages(state.TransitiveDependencies) {
+			packages = append(packages, newListEntry(*dep, "transitive"))
+		}
+		for _, dep := range sortedLocalPackages(state.LocalDependencies) {
+			_, err := os.Stat(state.LocalPath(*dep))
+			packages = append(packages, listEntry{
+				Name:      dep.Name,
+				Kind:      "local",
+				Path:      state.LocalPath(*dep),
+				Installed: err == nil,
+			})
+		}
+
+		if _, ok := options["json"]; ok {
+			return printJSON(packages)
+		}
+		if len(packages) == 0 {
+			fmt.Println("no packages")
+			return nil
+		}
+		table := [][]string{{"NAME", "VERSION", "KIND", "ALTS", "STATUS"}}
+		for _, pkg := range packages {
+			version := pkg.Version
+			if pkg.Kind == "local" {
+				version = pkg.Path
+			}
+			status := "installed"
+			if !pkg.Installed {
+				status = "missing"
+			}
+			table = append(table, []string{
+				pkg.Name, version, pkg.Kind, strings.Join(pkg.AlternativeNames, ","), status,
+			})
+		}
+		fmt.Println(cmd.FormatTable(table, "  ", "\n", ""))
+		return nil
+	},
+}
+
+// printJSON prints the given value as formatted JSON.
+func printJSON(v any) error {
+	raw, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(raw))
+	return nil
+}
+
+// sortedLocalPackages returns the given local packages sorted by name.
+func sortedLocalPackages(packages map[string]*config.PackageInfoLocal) []*config.PackageInfoLocal {
+	var sorted []*config.PackageInfoLocal
+	for _, pkg := range packages {
+		sorted = append(sorted, pkg)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+type ListError struct {
+	Err error
+}
+
+func NewListError(err error) *ListError {
+	return &ListError{
+		Err: err,
+	}
+}
+
+func (e ListError) Error() string {
+	return fmt.Sprintf("list error: %s", e.Err)
+}
+
+type listEntry struct {
+	Name             string   `json:"name"`
+	Kind             string   `json:"kind"`
+	AlternativeNames []string `json:"alts,omitempty"`
+	Repository       string   `json:"repository,omitempty"`
+	Version          string   `json:"version,omitempty"`
+	Path             string   `json:"path"`
+	Installed        bool     `json:"installed"`
+}
+
+func newListEntry(dep config.PackageInfoRemote, kind string) listEntry {
+	_, err := os.Stat(dep.RelativePath())
+	return listEntry{
+		Name:             dep.Name,
+		Kind:             kind,
+		AlternativeNames: dep.AlternativeNames,
+		Repository:       dep.Repository,
+		Version:          dep.Version,
+		Path:             dep.RelativePath(),
+		Installed:        err == nil,
+	}
+}
@@ -3,16 +3,26 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/config/schema"
 	"github.com/internet-computer/oko/internal/cmd"
+	"github.com/internet-computer/oko/mops"
 	"github.com/internet-computer/oko/vessel"
 )
 
 var MigrateCommand = cmd.Command{
-	Name:        "migrate",
-	Summary:     "migrate Vessel packages",
-	Description: `Allows you to migrate Vessel config files to Oko.`,
+	Name:    "migrate",
+	Summary: "migrate Vessel or mops packages",
+	Description: "Allows you to migrate Vessel or mops config files to Oko.\n\n" +
+		"Packages with the same repository and version are merged into one package with alternative names. " +
+		"Use `--schema` to upgrade the Oko package file to the latest version of the format. " +
+		"Use `--from mops` to migrate a `mops.toml` file. " +
+		"Packages from the mops registry are looked up in the package set specified with `--set`, otherwise the default Vessel package set is used.\n\n" +
+		"Frozen imports (i.e. imports with a sha256 hash) in Vessel files are read from the standard Dhall cache. " +
+		"Use `--mirror` to read remote imports from a local directory, i.e. `https://{host}/{path}` from `{mirror}/{host}/{path}`, and `--offline` to never fetch remote imports.",
 	Options: []cmd.Option{
 		{
 			Name:     "delete",
@@ -22,49 +32,158 @@ var MigrateCommand = cmd.Command{
 			Name:     "keep",
 			HasValue: false,
 		},
+		{
+			Name:     "schema",
+			Summary:  "upgrade the Oko package file",
+			HasValue: false,
+		},
+		{
+			Name:     "from",
+			Summary:  "vessel or mops",
+			HasValue: true,
+		},
+		{
+			Name:     "set",
+			Summary:  "package set location",
+			HasValue: true,
+		},
+		{
+			Name:     "mirror",
+			Summary:  "directory with local copies of remote imports",
+			HasValue: true,
+		},
+		{
+			Name:     "offline",
+			HasValue: false,
+		},
 	},
 	Method: func(_ []string, options map[string]string) error {
-		if 2 <= len(options) {
+		_, del := options["delete"]
+		_, keep := options["keep"]
+		if del && keep {
 			return NewMigrateError(NewOptionsError("can not use both `delete` and `keep` at the same time"))
 		}
-		if _, err := config.LoadPackageState("./oko.json"); err == nil {
-			return NewMigrateError(err)
-		}
-
-		manifest, err := vessel.LoadManifest("./vessel.dhall")
-		if err != nil {
-			return NewMigrateError(err)
+		if _, ok := options["schema"]; ok {
+			if err := migrateSchema(); err != nil {
+				return NewMigrateError(err)
+			}
+			return nil
 		}
-		packageSet, err := vessel.LoadPackageSet("./package-set.dhall")
+		l, err := config.LockManifest(config.NewManifestPath())
 		if err != nil {
 			return NewMigrateError(err)
 		}
+		defer l.Unlock()
 
-		packages, err := packageSet.Filter(manifest.Dependencies)
-		if err != nil {
+		if _, err := config.LoadPackageState(config.NewManifestPath()); err == nil {
 			return NewMigrateError(err)
 		}
 
-		if err := manifest.Save("./oko.json", packages); err != nil {
-			return NewMigrateError(err)
+		var files []string
+		switch from := options["from"]; from {
+		case "", "vessel":
+			resolver := vessel.NewResolver()
+			resolver.MirrorDir = options["mirror"]
+			_, resolver.Offline = options["offline"]
+			if err := migrateVessel(resolver); err != nil {
+				return NewMigrateError(err)
+			}
+			files = []string{config.NewPath("vessel.dhall"), config.NewPath("package-set.dhall")}
+		case "mops":
+			if err := migrateMops(packageSetLocation(options)); err != nil {
+				return NewMigrateError(err)
+			}
+			files = []string{config.NewPath("mops.toml")}
+		default:
+			return NewMigrateError(NewOptionsError(fmt.Sprintf("unknown format %q, expected `vessel` or `mops`", from)))
 		}
 
 		// Optional delete.
-		if _, ok := options["keep"]; ok {
+		if keep {
 			return nil
 		}
-		if _, ok := options["delete"]; ok || cmd.AskForConfirmation("Do you want to delete the `vessel.dhall` and `package-set.dhall` file?") {
-			if err := os.Remove("./vessel.dhall"); err != nil {
-				return NewMigrateError(err)
-			}
-			if err := os.Remove("./package-set.dhall"); err != nil {
-				return NewMigrateError(err)
+		var names []string
+		for _, file := range files {
+			names = append(names, fmt.Sprintf("`%s`", filepath.Base(file)))
+		}
+		if del || cmd.AskForConfirmation(fmt.Sprintf("Do you want to delete the %s file?", strings.Join(names, " and "))) {
+			for _, file := range files {
+				if err := os.Remove(file); err != nil {
+					return NewMigrateError(err)
+				}
 			}
 		}
 		return nil
 	},
 }
 
+// migrateMops migrates the `mops.toml` file to an Oko package file.
+func migrateMops(location string) error {
+	manifest, err := mops.LoadManifest(config.NewPath("mops.toml"))
+	if err != nil {
+		return err
+	}
+	packages, err := resolveMops(manifest, location)
+	if err != nil {
+		return err
+	}
+	return config.NewPackageState(&config.PackageConfig{
+		CompilerVersion:        manifest.Compiler,
+		Dependencies:           packages.Dependencies,
+		LocalDependencies:      packages.LocalDependencies,
+		TransitiveDependencies: packages.TransitiveDependencies,
+	}).Save(config.NewManifestPath())
+}
+
+// migrateSchema upgrades the Oko package file to the latest version.
+func migrateSchema() error {
+	l, err := config.LockManifest(config.ManifestPath())
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	raw, err := os.ReadFile(config.ManifestPath())
+	if err != nil {
+		return err
+	}
+	_, version, err := config.Migrate(raw)
+	if err != nil {
+		return err
+	}
+	if version == schema.LatestVersion {
+		fmt.Printf("already at version %d\n", version)
+		return nil
+	}
+	state, err := config.LoadPackageState(config.ManifestPath())
+	if err != nil {
+		return err
+	}
+	if err := state.Save(config.ManifestPath()); err != nil {
+		return err
+	}
+	fmt.Printf("upgraded from version %d to %d\n", version, schema.LatestVersion)
+	return nil
+}
+
+// migrateVessel migrates the `vessel.dhall` and `package-set.dhall` files to
+// an Oko package file.
+func migrateVessel(resolver vessel.Resolver) error {
+	manifest, err := resolver.LoadManifest(config.NewPath("vessel.dhall"))
+	if err != nil {
+		return err
+	}
+	packageSet, err := resolver.LoadPackageSet(config.NewPath("package-set.dhall"))
+	if err != nil {
+		return err
+	}
+	packages, err := packageSet.Filter(manifest.Dependencies)
+	if err != nil {
+		return err
+	}
+	return manifest.Save(config.NewManifestPath(), packages)
+}
+
 type MigrateError struct {
 	Err error
 }
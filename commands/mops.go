@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/github"
+	"github.com/internet-computer/oko/mops"
+	"github.com/internet-computer/oko/vessel"
+)
+
+// mopsPackages are the Oko packages of a mops manifest.
+type mopsPackages struct {
+	Dependencies           []config.PackageInfoRemote
+	LocalDependencies      []config.PackageInfoLocal
+	TransitiveDependencies []config.PackageInfoRemote
+}
+
+// names returns the names of the (remote) dependencies.
+func (p mopsPackages) names() []string {
+	var names []string
+	for _, dep := range p.Dependencies {
+		names = append(names, dep.Name)
+	}
+	return names
+}
+
+// resolveMops maps the dependencies of the given mops manifest to Oko packages.
+// GitHub hosted dependencies are used as is, the latest release is used if no
+// version is specified. Packages from the mops registry are looked up in the
+// package set at the given location, together with their dependencies.
+// Packages that can not be found are skipped with a warning.
+func resolveMops(manifest *mops.Manifest, location string) (*mopsPackages, error) {
+	var (
+		packages mopsPackages
+		set      *vessel.PackageSet
+		names    = make(map[string]bool)
+	)
+	for _, dep := range manifest.Dependencies {
+		names[dep.Name] = true
+	}
+	for _, dep := range manifest.Dependencies {
+		switch {
+		case dep.IsLocal():
+			packages.LocalDependencies = append(packages.LocalDependencies, config.PackageInfoLocal{
+				Name: dep.Name,
+				Path: dep.Path,
+			})
+		case dep.IsGitHub():
+			info := dep.Oko()
+			if info.Version == "" {
+				release, err := github.LatestRelease(strings.TrimPrefix(info.Repository, github.URL+"/"))
+				if err != nil {
+					return nil, err
+				}
+				info.Version = release.TagName
+			}
+			packages.Dependencies = append(packages.Dependencies, info)
+		default:
+			if set == nil {
+				s, err := vessel.LoadIndex(location)
+				if err != nil {
+					return nil, err
+				}
+				set = s
+			}
+			filtered, err := set.Filter([]string{dep.Name})
+			if err != nil {
+				fmt.Printf("skipping %q: not found in the package set\n", dep.Name)
+				continue
+			}
+			for _, pkg := range filtered.Oko() {
+				if pkg.Name != dep.Name {
+					if !names[pkg.Name] {
+						names[pkg.Name] = true
+						packages.TransitiveDependencies = append(packages.TransitiveDependencies, pkg)
+					}
+					continue
+				}
+				if pkg.Version != dep.Version {
+					fmt.Printf("using %q version %q of the package set instead of %q\n", dep.Name, pkg.Version, dep.Version)
+				}
+				packages.Dependencies = append(packages.Dependencies, pkg)
+			}
+		}
+	}
+	return &packages, nil
+}
@@ -14,14 +14,20 @@ var RemoveCommand = cmd.Command{
 	Description: `Allows you to remove packages by name.`,
 	Args:        []string{"name"},
 	Method: func(args []string, _ map[string]string) error {
-		state, err := config.LoadPackageState("./oko.json")
+		l, err := config.LockManifest(config.ManifestPath())
+		if err != nil {
+			return NewRemoveError(err)
+		}
+		defer l.Unlock()
+
+		state, err := config.LoadPackageState(config.ManifestPath())
 		if err != nil {
 			return NewRemoveError(err)
 		}
 
 		name := args[0]
 		if err := state.RemoveLocalPackage(name); err == nil {
-			if err := state.Save("./oko.json"); err != nil {
+			if err := state.Save(config.ManifestPath()); err != nil {
 				return NewRemoveError(err)
 			}
 			return nil
@@ -30,7 +36,7 @@ var RemoveCommand = cmd.Command{
 		if err := state.RemovePackage(name); err != nil {
 			return NewRemoveError(err)
 		}
-		if err := state.Save("./oko.json"); err != nil {
+		if err := state.Save(config.ManifestPath()); err != nil {
 			return NewRemoveError(err)
 		}
 		return nil
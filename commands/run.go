@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+var RunCommand = cmd.Command{
+	Name:    "run",
+	Summary: "run package scripts",
+	Description: "Runs a script declared in the `scripts` field of the Oko package file, in the directory of the package file.\n\n" +
+		"The bin dir of the Motoko compiler is prepended to `PATH`, " +
+		"`OKO_SOURCES` contains the package sources and `OKO_MOC` the path to the compiler. " +
+		"All arguments after `--` are appended to the script.",
+	Args:     []string{"script"},
+	Variadic: true,
+	Method: func(args []string, _ map[string]string) error {
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewRunError(err)
+		}
+
+		script, ok := state.Scripts[args[0]]
+		if !ok {
+			return NewRunError(NewScriptNotFoundError(args[0]))
+		}
+		env, err := toolchainEnv(state)
+		if err != nil {
+			return NewRunError(err)
+		}
+
+		run := exec.Command("sh", append([]string{"-c", fmt.Sprintf(`%s "$@"`, script), args[0]}, args[1:]...)...)
+		run.Env = env
+		run.Dir = config.Root()
+		run.Stdin = os.Stdin
+		run.Stdout = os.Stdout
+		run.Stderr = os.Stderr
+		if err := run.Run(); err != nil {
+			return NewRunError(err)
+		}
+		return nil
+	},
+}
+
+// toolchainEnv returns the environment of the current process extended with
+// the toolchain of the given package state. Downloads the compiler if it is
+// not present yet.
+func toolchainEnv(state *config.PackageState) ([]string, error) {
+	var (
+		env  = os.Environ()
+		path []string
+	)
+	if state.CompilerVersion != nil {
+		moc, err := compilerTool(state, "moc")
+		if err != nil {
+			return nil, err
+		}
+		if moc, err = filepath.Abs(moc); err != nil {
+			return nil, err
+		}
+		path = append(path, filepath.Dir(moc))
+		env = append(env, fmt.Sprintf("OKO_MOC=%s", moc))
+	}
+	if state.DidcVersion != nil {
+		didc, err := filepath.Abs(didcPath(*state.DidcVersion))
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, filepath.Dir(didc))
+	}
+	path = append(path, os.Getenv("PATH"))
+
+	// The sources are absolute, since scripts do not run in the working directory.
+	sources := packageSources(state)
+	for i := 2; i < len(sources); i += 3 {
+		abs, err := filepath.Abs(sources[i])
+		if err != nil {
+			return nil, err
+		}
+		sources[i] = abs
+	}
+	return append(
+		env,
+		fmt.Sprintf("PATH=%s", strings.Join(path, string(os.PathListSeparator))),
+		fmt.Sprintf("OKO_SOURCES=%s", strings.Join(sources, " ")),
+	), nil
+}
+
+type RunError struct {
+	Err error
+}
+
+func NewRunError(err error) *RunError {
+	return &RunError{
+		Err: err,
+	}
+}
+
+func (e RunError) Error() string {
+	return fmt.Sprintf("run error: %s", e.Err)
+}
+
+func (e RunError) Unwrap() error {
+	return e.Err
+}
+
+type ScriptNotFoundError struct {
+	Name string
+}
+
+func NewScriptNotFoundError(name string) *ScriptNotFoundError {
+	return &ScriptNotFoundError{
+		Name: name,
+	}
+}
+
+func (e ScriptNotFoundError) Error() string {
+	return fmt.Sprintf("script %q not found", e.Name)
+}
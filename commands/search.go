@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/github"
+	"github.com/internet-computer/oko/internal/cmd"
+	"github.com/internet-computer/oko/vessel"
+)
+
+var SearchCommand = cmd.Command{
+	Name:    "search",
+	Aliases: []string{"s"},
+	Summary: "search packages",
+	Description: "Searches a package set for packages of which the name or description contains the given query.\n\n" +
+		"The package set is either a Vessel package set (`package-set.dhall`) or a JSON package set (`*.json`), loaded from a URL or a local file. " +
+		"It can be specified with `--set` or the `packageSet` field of the Oko package file, otherwise the default Vessel package set is used.\n\n" +
+		"Use `--latest` to look up the latest releases on GitHub.",
+	Args: []string{"query"},
+	Options: []cmd.Option{
+		{
+			Name:     "set",
+			Summary:  "package set location",
+			HasValue: true,
+		},
+		{
+			Name:     "latest",
+			HasValue: false,
+		},
+	},
+	Method: func(args []string, options map[string]string) error {
+		set, err := vessel.LoadIndex(packageSetLocation(options))
+		if err != nil {
+			return NewSearchError(err)
+		}
+
+		packages := set.Search(args[0])
+		if len(packages) == 0 {
+			fmt.Println("no packages found")
+			return nil
+		}
+		_, latest := options["latest"]
+		table := [][]string{{"NAME", "VERSION", "REPOSITORY", "DESCRIPTION"}}
+		if latest {
+			table[0] = []string{"NAME", "VERSION", "LATEST", "REPOSITORY", "DESCRIPTION"}
+		}
+		for _, pkg := range packages {
+			row := []string{pkg.Name, pkg.Version}
+			if latest {
+				row = append(row, latestVersion(pkg.Repo))
+			}
+			table = append(table, append(row, pkg.Repo, pkg.Description))
+		}
+		fmt.Println(cmd.FormatTable(table, "  ", "\n", ""))
+		return nil
+	},
+}
+
+// latestVersion returns the latest release of the given GitHub repository.
+// Returns "-" if the latest release could not be found.
+func latestVersion(repository string) string {
+	repo := strings.TrimSuffix(strings.TrimPrefix(repository, github.URL+"/"), ".git")
+	if repo == repository {
+		// Not a GitHub repository.
+		return "-"
+	}
+	release, err := github.LatestRelease(repo)
+	if err != nil {
+		return "-"
+	}
+	return release.TagName
+}
+
+// packageSetLocation returns the location of the package set. Checks the
+// options, the package file and falls back to the default package set.
+func packageSetLocation(options map[string]string) string {
+	if location, ok := options["set"]; ok {
+		return location
+	}
+	if state, err := config.LoadPackageState(config.ManifestPath()); err == nil && state.PackageSet != nil {
+		return *state.PackageSet
+	}
+	return vessel.DefaultIndex
+}
+
+type SearchError struct {
+	Err error
+}
+
+func NewSearchError(err error) *SearchError {
+	return &SearchError{
+		Err: err,
+	}
+}
+
+func (e SearchError) Error() string {
+	return fmt.Sprintf("search error: %s", e.Err)
+}
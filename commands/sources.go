@@ -12,42 +12,38 @@ var SourcesCommand = cmd.Command{
 	Name:    "sources",
 	Summary: "prints moc package sources",
 	Method: func(_ []string, _ map[string]string) error {
-		state, err := config.LoadPackageState("./oko.json")
+		state, err := config.LoadPackageState(config.ManifestPath())
 		if err != nil {
 			return NewSourcesError(err)
 		}
 
-		var sources []string
-		for _, dep := range state.Dependencies {
-			sources = append(sources, fmt.Sprintf(
-				"--package %s %s/src", dep.Name, dep.RelativePath(),
-			))
-			for _, name := range dep.AlternativeNames {
-				sources = append(sources, fmt.Sprintf(
-					"--package %s %s/src", name, dep.RelativePath(),
-				))
-			}
-		}
-		for _, dep := range state.TransitiveDependencies {
-			sources = append(sources, fmt.Sprintf(
-				"--package %s %s/src", dep.Name, dep.RelativePath(),
-			))
-			for _, name := range dep.AlternativeNames {
-				sources = append(sources, fmt.Sprintf(
-					"--package %s %s/src", name, dep.RelativePath(),
-				))
-			}
-		}
-		for _, dep := range state.LocalDependencies {
-			sources = append(sources, fmt.Sprintf(
-				"--package %s %s", dep.Name, dep.Path,
-			))
-		}
+		sources := packageSources(state)
 		fmt.Print(strings.Join(sources, " "))
 		return nil
 	},
 }
 
+// packageSources returns the `--package` flags of all dependencies.
+func packageSources(state *config.PackageState) []string {
+	var sources []string
+	for _, dep := range state.Dependencies {
+		sources = append(sources, "--package", dep.Name, dep.SourcePath())
+		for _, name := range dep.AlternativeNames {
+			sources = append(sources, "--package", name, dep.SourcePath())
+		}
+	}
+	for _, dep := range state.TransitiveDependencies {
+		sources = append(sources, "--package", dep.Name, dep.SourcePath())
+		for _, name := range dep.AlternativeNames {
+			sources = append(sources, "--package", name, dep.SourcePath())
+		}
+	}
+	for _, dep := range state.LocalDependencies {
+		sources = append(sources, "--package", dep.Name, state.LocalPath(*dep))
+	}
+	return sources
+}
+
 type SourcesError struct {
 	Err error
 }
@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+	"github.com/internet-computer/oko/internal/junit"
+)
+
+var TestCommand = cmd.Command{
+	Name:    "test",
+	Aliases: []string{"t"},
+	Summary: "run Motoko tests",
+	Description: "Runs all Motoko test files (`*.test.mo`) with the Motoko compiler specified in the Oko package file.\n\n" +
+		"A test passes if it exits successfully. " +
+		"By default tests are run with the interpreter (`moc -r`), " +
+		"the `wasi` mode compiles them to Wasm and runs them with `wasmtime` instead.",
+	Options: []cmd.Option{
+		{
+			Name:     "pattern",
+			Summary:  "glob pattern of test files",
+			HasValue: true,
+		},
+		{
+			Name:     "filter",
+			Summary:  "only run tests that contain the given string",
+			HasValue: true,
+		},
+		{
+			Name:     "parallel",
+			Summary:  "number of tests run in parallel",
+			HasValue: true,
+		},
+		{
+			Name:     "mode",
+			Summary:  "interpreter or wasi",
+			HasValue: true,
+		},
+		{
+			Name:     "junit",
+			Summary:  "path of the JUnit XML report",
+			HasValue: true,
+		},
+	},
+	Method: func(_ []string, options map[string]string) error {
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewTestError(err)
+		}
+
+		pattern := "*.test.mo"
+		if p, ok := options["pattern"]; ok {
+			pattern = p
+		}
+		parallel := 1
+		if p, ok := options["parallel"]; ok {
+			if parallel, err = strconv.Atoi(p); err != nil || parallel < 1 {
+				return NewTestError(NewOptionsError("`parallel` expects a positive number"))
+			}
+		}
+		runner := testRunner{
+			sources: packageSources(state),
+		}
+		switch mode := options["mode"]; mode {
+		case "", "interpreter":
+		case "wasi":
+			runner.wasi = true
+		default:
+			return NewTestError(NewOptionsError(fmt.Sprintf("unknown mode %q", mode)))
+		}
+
+		tests, err := findTests(".", pattern, options["filter"])
+		if err != nil {
+			return NewTestError(err)
+		}
+		if len(tests) == 0 {
+			fmt.Println("no test files found")
+			return nil
+		}
+
+		if runner.moc, err = compilerTool(state, "moc"); err != nil {
+			return NewTestError(err)
+		}
+		if runner.wasi {
+			if runner.wasmtime, err = wasmtimePath(state); err != nil {
+				return NewTestError(err)
+			}
+		}
+
+		results := runner.run(tests, parallel)
+		var (
+			cases  []junit.TestCase
+			failed int
+		)
+		for _, r := range results {
+			c := junit.NewTestCase(r.path, filepath.Dir(r.path), r.duration)
+			if r.err != nil {
+				failed++
+				c.Failure = &junit.Failure{
+					Message:  r.err.Error(),
+					Contents: r.output,
+				}
+				fmt.Printf("FAIL %s (%.2fs)\n", r.path, r.duration.Seconds())
+				if out := strings.TrimSpace(r.output); out != "" {
+					for _, line := range strings.Split(out, "\n") {
+						fmt.Printf("\t%s\n", line)
+					}
+				}
+			} else {
+				c.SystemOut = r.output
+				fmt.Printf("PASS %s (%.2fs)\n", r.path, r.duration.Seconds())
+			}
+			cases = append(cases, c)
+		}
+		fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+
+		if path, ok := options["junit"]; ok {
+			if err := junit.Save(path, junit.NewTestSuite("oko", cases)); err != nil {
+				return NewTestError(err)
+			}
+		}
+		if failed != 0 {
+			return NewTestError(NewTestsFailedError(failed, len(results)))
+		}
+		return nil
+	},
+}
+
+func combinedOutput(name string, args ...string) (string, error) {
+	var out bytes.Buffer
+	c := exec.Command(name, args...)
+	c.Stdout = &out
+	c.Stderr = &out
+	err := c.Run()
+	return out.String(), err
+}
+
+// findTests returns all files in the given directory that match the given glob
+// pattern and contain the given filter. Patterns without a path separator are
+// matched against the file name. Hidden directories (e.g. `.oko`) are skipped.
+func findTests(root, pattern, filter string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, NewOptionsError(fmt.Sprintf("invalid pattern %q", pattern))
+	}
+	var tests []string
+	if err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		if strings.Contains(pattern, "/") {
+			name = filepath.ToSlash(path)
+		}
+		if ok, _ := filepath.Match(pattern, name); ok && strings.Contains(path, filter) {
+			tests = append(tests, path)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tests, nil
+}
+
+// wasmtimePath returns the path to wasmtime, preferring the one in the bin dir
+// of the Motoko compiler.
+func wasmtimePath(state *config.PackageState) (string, error) {
+	if state.CompilerVersion != nil {
+		path := filepath.Join(compilerDir(*state.CompilerVersion), "wasmtime")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return exec.LookPath("wasmtime")
+}
+
+type TestError struct {
+	Err error
+}
+
+func NewTestError(err error) *TestError {
+	return &TestError{
+		Err: err,
+	}
+}
+
+func (e TestError) Error() string {
+	return fmt.Sprintf("test error: %s", e.Err)
+}
+
+type TestsFailedError struct {
+	Failed int
+	Total  int
+}
+
+func NewTestsFailedError(failed, total int) *TestsFailedError {
+	return &TestsFailedError{
+		Failed: failed,
+		Total:  total,
+	}
+}
+
+func (e TestsFailedError) Error() string {
+	return fmt.Sprintf("%d of %d test(s) failed", e.Failed, e.Total)
+}
+
+type testResult struct {
+	path     string
+	duration time.Duration
+	output   string
+	err      error
+}
+
+type testRunner struct {
+	moc      string
+	wasmtime string
+	wasi     bool
+	sources  []string
+}
+
+// mocArguments returns the package sources followed by the given arguments.
+func (r testRunner) mocArguments(args ...string) []string {
+	return append(append([]string{}, r.sources...), args...)
+}
+
+// run runs the given tests, at most n at a time. The results are returned in
+// the same order as the tests.
+func (r testRunner) run(tests []string, n int) []testResult {
+	var (
+		results = make([]testResult, len(tests))
+		sem     = make(chan struct{}, n)
+		wg      sync.WaitGroup
+	)
+	for i, path := range tests {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, path string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := time.Now()
+			output, err := r.runTest(path)
+			results[i] = testResult{
+				path:     path,
+				duration: time.Since(start),
+				output:   output,
+				err:      err,
+			}
+		}(i, path)
+	}
+	wg.Wait()
+	return results
+}
+
+// runTest runs a single test file and returns its (combined) output.
+func (r testRunner) runTest(path string) (string, error) {
+	if !r.wasi {
+		return combinedOutput(r.moc, r.mocArguments("-r", path)...)
+	}
+
+	dir, err := os.MkdirTemp("", "oko-test")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	wasm := filepath.Join(dir, "test.wasm")
+	if out, err := combinedOutput(r.moc, r.mocArguments("-wasi-system-api", "-o", wasm, path)...); err != nil {
+		return out, err
+	}
+	return combinedOutput(r.wasmtime, wasm)
+}
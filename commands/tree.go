@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+	"golang.org/x/exp/slices"
+)
+
+var TreeCommand = cmd.Command{
+	Name:    "tree",
+	Summary: "print the dependency tree",
+	Description: "Prints the dependency tree of the package.\n\n" +
+		"Local packages declared by a remote package are printed below that package. " +
+		"Packages that depend on one of their ancestors are marked with `(cycle)`.",
+	Method: func(_ []string, _ map[string]string) error {
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewTreeError(err)
+		}
+
+		root := "."
+		if state.Package != nil {
+			root = state.Package.Name
+		}
+		fmt.Println(root)
+		var children []treeNode
+		for _, dep := range sortedPackages(state.Dependencies) {
+			children = append(children, treeNode{name: dep.Name})
+		}
+		for _, dep := range sortedLocalPackages(state.LocalDependencies) {
+			if dep.Parent == "" {
+				children = append(children, treeNode{name: dep.Name, local: true})
+			}
+		}
+		printTree(state, children, "", nil)
+		return nil
+	},
+}
+
+// treeNode is a (remote or local) package in the dependency tree.
+type treeNode struct {
+	name  string
+	local bool
+}
+
+// printTree prints the given packages and their dependencies, with the given
+// prefix. The ancestors are the names of the packages above the packages.
+func printTree(state *config.PackageState, nodes []treeNode, prefix string, ancestors []string) {
+	for i, node := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		if node.local {
+			label := node.name
+			if dep, ok := state.LocalDependencies[node.name]; ok {
+				label = fmt.Sprintf("%s (%s)", node.name, state.LocalPath(*dep))
+			}
+			fmt.Printf("%s%s%s\n", prefix, branch, label)
+			continue
+		}
+		dep := state.GetByName(node.name)
+		if dep == nil {
+			if local, ok := state.LocalDependencies[node.name]; ok {
+				fmt.Printf("%s%s%s (%s)\n", prefix, branch, node.name, state.LocalPath(*local))
+			} else {
+				fmt.Printf("%s%s%s (missing)\n", prefix, branch, node.name)
+			}
+			continue
+		}
+		if slices.Contains(ancestors, dep.Name) {
+			fmt.Printf("%s%s%s %s (cycle)\n", prefix, branch, node.name, dep.Version)
+			continue
+		}
+		fmt.Printf("%s%s%s %s\n", prefix, branch, node.name, dep.Version)
+
+		var children []treeNode
+		names := append([]string{}, dep.Dependencies...)
+		sort.Strings(names)
+		for _, name := range names {
+			children = append(children, treeNode{name: name})
+		}
+		for _, local := range sortedLocalPackages(state.LocalDependencies) {
+			if local.Parent == dep.Name && !slices.Contains(names, local.Name) {
+				children = append(children, treeNode{name: local.Name, local: true})
+			}
+		}
+		printTree(state, children, prefix+indent, append(ancestors, dep.Name))
+	}
+}
+
+type TreeError struct {
+	Err error
+}
+
+func NewTreeError(err error) *TreeError {
+	return &TreeError{
+		Err: err,
+	}
+}
+
+func (e TreeError) Error() string {
+	return fmt.Sprintf("tree error: %s", e.Err)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+var UpdateCommand = cmd.Command{
+	Name:    "update",
+	Aliases: []string{"u"},
+	Summary: "update packages",
+	Description: "Updates all packages that were installed from a package set to the versions in that package set, together with their dependencies.\n\n" +
+		"Packages installed from GitHub or linked locally are left as is.",
+	Options: []cmd.Option{
+		{
+			Name:     "dry-run",
+			Summary:  "only print the updates",
+			HasValue: false,
+		},
+	},
+	Method: func(_ []string, options map[string]string) error {
+		l, err := config.LockManifest(config.ManifestPath())
+		if err != nil {
+			return NewUpdateError(err)
+		}
+		defer l.Unlock()
+
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewUpdateError(err)
+		}
+
+		_, dryRun := options["dry-run"]
+		for _, dep := range sortedPackages(state.Dependencies) {
+			if dep.Set == "" {
+				continue
+			}
+			info, dependencies, err := packageSetPackages(dep.Set, dep.Name)
+			if err != nil {
+				return NewUpdateError(err)
+			}
+			if info.Version != dep.Version {
+				fmt.Printf("%s: %s -> %s\n", dep.Name, dep.Version, info.Version)
+			}
+			if dryRun {
+				continue
+			}
+			if err := state.UpdatePackage(info, dependencies...); err != nil {
+				return NewUpdateError(err)
+			}
+			if err := downloadPackages(state, append(dependencies, info)...); err != nil {
+				return NewUpdateError(err)
+			}
+		}
+		if dryRun {
+			return nil
+		}
+		if err := state.Save(config.ManifestPath()); err != nil {
+			return NewUpdateError(err)
+		}
+		return nil
+	},
+}
+
+type UpdateError struct {
+	Err error
+}
+
+func NewUpdateError(err error) *UpdateError {
+	return &UpdateError{
+		Err: err,
+	}
+}
+
+func (e UpdateError) Error() string {
+	return fmt.Sprintf("update error: %s", e.Err)
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/internal/cmd"
+)
+
+var VerifyCommand = cmd.Command{
+	Name:    "verify",
+	Summary: "verify downloaded packages",
+	Description: "Verifies that all packages specified in the Oko package file are downloaded, complete and match their recorded hash.\n\n" +
+		"Broken packages are downloaded again if `--fix` is specified.",
+	Options: []cmd.Option{
+		{
+			Name:     "fix",
+			HasValue: false,
+		},
+	},
+	Method: func(_ []string, options map[string]string) error {
+		l, err := config.LockManifest(config.ManifestPath())
+		if err != nil {
+			return NewVerifyError(err)
+		}
+		defer l.Unlock()
+
+		state, err := config.LoadPackageState(config.ManifestPath())
+		if err != nil {
+			return NewVerifyError(err)
+		}
+		_, fix := options["fix"]
+
+		var broken int
+		for _, dep := range append(sortedPackages(state.Dependencies), sortedPackages(state.TransitiveDependencies)...) {
+			if err := dep.Verify(); err != nil {
+				if fix {
+					if err = dep.Redownload(); err == nil {
+						fmt.Printf("FIXED %s\n", dep.Name)
+						continue
+					}
+				}
+				broken++
+				fmt.Printf("BROKEN %s: %s\n", dep.Name, err)
+				continue
+			}
+			if dep.Hash == "" {
+				fmt.Printf("OK %s (no recorded hash)\n", dep.Name)
+				continue
+			}
+			fmt.Printf("OK %s\n", dep.Name)
+		}
+		for _, dep := range state.LocalDependencies {
+			if _, err := os.Stat(state.LocalPath(*dep)); err != nil {
+				broken++
+				fmt.Printf("BROKEN %s: %s\n", dep.Name, NewPathNotFoundError(state.LocalPath(*dep)))
+				continue
+			}
+			fmt.Printf("OK %s\n", dep.Name)
+		}
+
+		if fix {
+			// Record the hashes of the fixed packages.
+			if err := state.Save(config.ManifestPath()); err != nil {
+				return NewVerifyError(err)
+			}
+		}
+		if broken != 0 {
+			return NewVerifyError(NewBrokenPackagesError(broken))
+		}
+		return nil
+	},
+}
+
+// sortedPackages returns the given packages sorted by name.
+func sortedPackages(packages map[string]*config.PackageInfoRemote) []*config.PackageInfoRemote {
+	var sorted []*config.PackageInfoRemote
+	for _, pkg := range packages {
+		sorted = append(sorted, pkg)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+type BrokenPackagesError struct {
+	Count int
+}
+
+func NewBrokenPackagesError(count int) *BrokenPackagesError {
+	return &BrokenPackagesError{
+		Count: count,
+	}
+}
+
+func (e BrokenPackagesError) Error() string {
+	return fmt.Sprintf("%d broken package(s)", e.Count)
+}
+
+type VerifyError struct {
+	Err error
+}
+
+func NewVerifyError(err error) *VerifyError {
+	return &VerifyError{
+		Err: err,
+	}
+}
+
+func (e VerifyError) Error() string {
+	return fmt.Sprintf("verify error: %s", e.Err)
+}
@@ -49,6 +49,44 @@ func (e PackageAlreadyExistsError) Error() string {
 	)
 }
 
+type PackageIncompleteError struct {
+	Name    string
+	Missing string
+}
+
+func NewPackageIncompleteError(name, missing string) *PackageIncompleteError {
+	return &PackageIncompleteError{
+		Name:    name,
+		Missing: missing,
+	}
+}
+
+func (e PackageIncompleteError) Error() string {
+	return fmt.Sprintf(
+		"package %q is incomplete: %q not found",
+		e.Name, e.Missing,
+	)
+}
+
+type PackageMissingError struct {
+	Name string
+	Path string
+}
+
+func NewPackageMissingError(name, path string) *PackageMissingError {
+	return &PackageMissingError{
+		Name: name,
+		Path: path,
+	}
+}
+
+func (e PackageMissingError) Error() string {
+	return fmt.Sprintf(
+		"package %q is not downloaded: %q not found",
+		e.Name, e.Path,
+	)
+}
+
 type PackageNotFoundError struct {
 	Name string
 }
@@ -72,6 +110,25 @@ func (e PackageNotFoundError) Error() string {
 	)
 }
 
+type UnresolvedDependencyError struct {
+	PackageName    string
+	DependencyName string
+}
+
+func NewUnresolvedDependencyError(packageName, dependencyName string) *UnresolvedDependencyError {
+	return &UnresolvedDependencyError{
+		PackageName:    packageName,
+		DependencyName: dependencyName,
+	}
+}
+
+func (e UnresolvedDependencyError) Error() string {
+	return fmt.Sprintf(
+		"package %q depends on %q, which is not provided by any package",
+		e.PackageName, e.DependencyName,
+	)
+}
+
 type ValidationError struct {
 	Err error
 }
@@ -88,3 +145,55 @@ func (e ValidationError) Error() string {
 		e.Err.Error(),
 	)
 }
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+type LocalPathOutsideError struct {
+	PackageName string
+	Path        string
+}
+
+func NewLocalPathOutsideError(packageName, path string) *LocalPathOutsideError {
+	return &LocalPathOutsideError{
+		PackageName: packageName,
+		Path:        path,
+	}
+}
+
+func (e LocalPathOutsideError) Error() string {
+	return fmt.Sprintf(
+		"package %q declares local package path %q, which is outside of the package",
+		e.PackageName, e.Path,
+	)
+}
+
+type SourceOutsideError struct {
+	PackageName string
+	Source      string
+}
+
+func NewSourceOutsideError(packageName, source string) *SourceOutsideError {
+	return &SourceOutsideError{
+		PackageName: packageName,
+		Source:      source,
+	}
+}
+
+func (e SourceOutsideError) Error() string {
+	return fmt.Sprintf(
+		"package %q declares source directory %q, which is outside of the package",
+		e.PackageName, e.Source,
+	)
+}
+
+type ManifestNotFoundError struct{}
+
+func NewManifestNotFoundError() *ManifestNotFoundError {
+	return &ManifestNotFoundError{}
+}
+
+func (e ManifestNotFoundError) Error() string {
+	return fmt.Sprintf("could not find %q in the current directory or any of its parents", ManifestName)
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/internet-computer/oko/internal"
+)
+
+// field is a key-value pair of a JSON object.
+type field struct {
+	key   string
+	value json.RawMessage
+}
+
+// parseObject returns the fields of the given JSON object, in order.
+func parseObject(raw []byte) ([]field, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := d.Token(); err != nil {
+		return nil, err
+	}
+	var fields []field
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := d.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field{
+			key:   t.(string),
+			value: value,
+		})
+	}
+	return fields, nil
+}
+
+// configKeys returns the keys of the package config, in the order they are
+// written to the package file.
+func configKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(PackageConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+// detectIndent returns the indentation used for the top-level keys of the
+// given JSON object. Returns an empty string if the object is compact.
+func detectIndent(raw []byte) string {
+	lines := strings.Split(string(raw), "\n")
+	if len(lines) == 1 {
+		return ""
+	}
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, "\"") {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "\t"
+}
+
+// equalJSON returns whether the given JSON values are semantically equal.
+func equalJSON(a, b []byte) bool {
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// merge writes the given package config over the original package file. Keys
+// that are unknown to the package config and values that did not change are
+// kept as is, new keys are inserted after their preceding keys. The
+// indentation of the original package file is preserved.
+func merge(original []byte, pkg PackageConfig) ([]byte, error) {
+	fields, err := parseObject(original)
+	if err != nil {
+		return nil, internal.Error(err)
+	}
+	raw, err := json.Marshal(pkg)
+	if err != nil {
+		return nil, internal.Error(err)
+	}
+	updates, err := parseObject(raw)
+	if err != nil {
+		return nil, internal.Error(err)
+	}
+	values := make(map[string]json.RawMessage)
+	for _, f := range updates {
+		values[f.key] = f.value
+	}
+	indent := detectIndent(original)
+
+	// Update or remove the existing keys.
+	known := make(map[string]bool)
+	for _, key := range configKeys() {
+		known[key] = true
+	}
+	var merged []field
+	for _, f := range fields {
+		if !known[f.key] {
+			merged = append(merged, f)
+			continue
+		}
+		value, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		delete(values, f.key)
+		if !equalJSON(f.value, value) {
+			f.value = indentValue(value, indent)
+		}
+		merged = append(merged, f)
+	}
+
+	// Insert the new keys.
+	next := 0
+	for _, key := range configKeys() {
+		if i := indexOf(merged, key); i != -1 {
+			next = i + 1
+			continue
+		}
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		merged = append(merged[:next], append([]field{{
+			key:   key,
+			value: indentValue(value, indent),
+		}}, merged[next:]...)...)
+		next++
+	}
+
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, f := range merged {
+		if i != 0 {
+			b.WriteString(",")
+		}
+		key, _ := json.Marshal(f.key)
+		if indent == "" {
+			b.Write(key)
+			b.WriteString(":")
+		} else {
+			b.WriteString("\n" + indent)
+			b.Write(key)
+			b.WriteString(": ")
+		}
+		b.Write(f.value)
+	}
+	if indent != "" && len(merged) != 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	if bytes.HasSuffix(bytes.TrimRight(original, " \t"), []byte("\n")) {
+		b.WriteString("\n")
+	}
+	return b.Bytes(), nil
+}
+
+// indentValue formats the given top-level value with the given indentation.
+func indentValue(value json.RawMessage, indent string) json.RawMessage {
+	if indent == "" {
+		return value
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, value, indent, indent); err != nil {
+		return value
+	}
+	return b.Bytes()
+}
+
+// indexOf returns the index of the field with the given key, or -1.
+func indexOf(fields []field, key string) int {
+	for i, f := range fields {
+		if f.key == key {
+			return i
+		}
+	}
+	return -1
+}
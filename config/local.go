@@ -3,14 +3,19 @@ package config
 type PackageInfoLocal struct {
 	Name string `json:"name"`
 	Path string `json:"path"`
+	// Parent is the name of the remote package that declares the local package,
+	// if any. The path is then relative to the directory of that package.
+	Parent string `json:"parent,omitempty"`
 }
 
 func (p PackageInfoLocal) GetName() string {
 	return p.Name
 }
 
+// RelativePath returns the path of the package relative to the working
+// directory. The path in the package file is relative to the root directory.
 func (p PackageInfoLocal) RelativePath() string {
-	return p.Path
+	return Path(p.Path)
 }
 
 // equals returns true if both the name and path.
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/internet-computer/oko/internal/lock"
+)
+
+// LockManifest acquires the lock on the package file at the given path. The
+// lock should be held while loading, modifying and saving the package file, so
+// concurrent commands do not overwrite each others changes.
+func LockManifest(path string) (*lock.Lock, error) {
+	l, err := lock.New(filepath.Join(filepath.Dir(path), ".oko", ".manifest.lock"))
+	if err != nil {
+		return nil, NewIOError(err)
+	}
+	return l, nil
+}
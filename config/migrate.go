@@ -0,0 +1,55 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/internet-computer/oko/config/schema"
+	"github.com/internet-computer/oko/internal"
+)
+
+// migrations contains the migration steps of the package file format, the
+// migration at index `i` upgrades version `i+1` to version `i+2`.
+var migrations = []func(pkg map[string]interface{}) error{
+	migrateV1,
+}
+
+// Migrate upgrades the given package file to the latest version. The package
+// file gets validated against the schema of every version it passes. Returns
+// the upgraded package file and the original version.
+func Migrate(raw []byte) ([]byte, int, error) {
+	version, err := schema.Version(raw)
+	if err != nil {
+		return nil, 0, NewValidationError(err)
+	}
+	if err := schema.ValidateVersion(raw, version); err != nil {
+		return nil, version, NewValidationError(err)
+	}
+	if version == schema.LatestVersion {
+		return raw, version, nil
+	}
+
+	var pkg map[string]interface{}
+	if err := json.Unmarshal(raw, &pkg); err != nil {
+		return nil, version, internal.Error(err)
+	}
+	upgraded := raw
+	for v := version; v < schema.LatestVersion; v++ {
+		if err := migrations[v-1](pkg); err != nil {
+			return nil, version, err
+		}
+		pkg["version"] = v + 1
+		if upgraded, err = json.Marshal(pkg); err != nil {
+			return nil, version, internal.Error(err)
+		}
+		if err := schema.ValidateVersion(upgraded, v+1); err != nil {
+			return nil, version, NewValidationError(err)
+		}
+	}
+	return upgraded, version, nil
+}
+
+// migrateV1 upgrades version 1 to version 2. Version 1 package files have no
+// version field, the format itself did not change.
+func migrateV1(_ map[string]interface{}) error {
+	return nil
+}
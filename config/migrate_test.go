@@ -0,0 +1,62 @@
+package config_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/config/schema"
+)
+
+func TestMigrate(t *testing.T) {
+	v1 := []byte(`{
+		"compiler": "0.7.4",
+		"dependencies": [
+			{
+				"name": "test",
+				"repository": "url",
+				"version": "v0.0.1",
+				"dependencies": [ "base" ]
+			}
+		],
+		"transitiveDependencies": [
+			{
+				"name": "base",
+				"repository": "url",
+				"version": "v0.0.1"
+			}
+		]
+	}`)
+	v2, version, err := config.Migrate(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 {
+		t.Error(version)
+	}
+	if v, _ := schema.Version(v2); v != schema.LatestVersion {
+		t.Error(v)
+	}
+	expected, _ := config.NewPackageConfig(v1)
+	actual, _ := config.NewPackageConfig(v2)
+	expected.Version = schema.LatestVersion
+	if !reflect.DeepEqual(expected, actual) {
+		t.Error(expected, actual)
+	}
+
+	// Already at the latest version.
+	if raw, version, err := config.Migrate(v2); err != nil || version != schema.LatestVersion || string(raw) != string(v2) {
+		t.Error(string(raw), version, err)
+	}
+
+	for _, raw := range []string{
+		`{}`,
+		`{ "version": 2 }`,
+		`{ "version": 3, "dependencies": [] }`,
+		`{ "version": "2", "dependencies": [] }`,
+	} {
+		if _, _, err := config.Migrate([]byte(raw)); err == nil {
+			t.Error(raw)
+		}
+	}
+}
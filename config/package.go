@@ -6,11 +6,23 @@ import (
 	"github.com/internet-computer/oko/internal"
 )
 
+// DefaultSource is the directory of the Motoko sources of a package, if not
+// specified otherwise.
+const DefaultSource = "src"
+
 type PackageConfig struct {
-	CompilerVersion        *string             `json:"compiler,omitempty"`
-	Dependencies           []PackageInfoRemote `json:"dependencies"`
-	LocalDependencies      []PackageInfoLocal  `json:"localDependencies,omitempty"`
-	TransitiveDependencies []PackageInfoRemote `json:"transitiveDependencies,omitempty"`
+	Version                int                    `json:"version"`
+	Package                *PackageMetadata       `json:"package,omitempty"`
+	Source                 *string                `json:"source,omitempty"`
+	CompilerVersion        *string                `json:"compiler,omitempty"`
+	DidcVersion            *string                `json:"didc,omitempty"`
+	Checksums              map[string]string      `json:"checksums,omitempty"`
+	PackageSet             *string                `json:"packageSet,omitempty"`
+	Dependencies           []PackageInfoRemote    `json:"dependencies"`
+	LocalDependencies      []PackageInfoLocal     `json:"localDependencies,omitempty"`
+	TransitiveDependencies []PackageInfoRemote    `json:"transitiveDependencies,omitempty"`
+	Targets                map[string]BuildTarget `json:"targets,omitempty"`
+	Scripts                map[string]string      `json:"scripts,omitempty"`
 }
 
 func NewPackageConfig(raw []byte) (*PackageConfig, error) {
@@ -20,3 +32,14 @@ func NewPackageConfig(raw []byte) (*PackageConfig, error) {
 	}
 	return &pkg, nil
 }
+
+// PackageMetadata describes the package itself, used when publishing it.
+type PackageMetadata struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version,omitempty"`
+	Description string   `json:"description,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Authors     []string `json:"authors,omitempty"`
+	Repository  string   `json:"repository,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+}
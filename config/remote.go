@@ -1,11 +1,11 @@
 package config
 
 import (
-	"fmt"
-	"strings"
+	"os"
+	"path/filepath"
 
 	"github.com/internet-computer/oko/internal"
-	"github.com/internet-computer/oko/internal/tar"
+	"github.com/internet-computer/oko/internal/checksum"
 	"golang.org/x/exp/slices"
 )
 
@@ -15,6 +15,12 @@ type PackageInfoRemote struct {
 	Repository       string   `json:"repository"`
 	Version          string   `json:"version"`
 	Dependencies     []string `json:"dependencies,omitempty"`
+	Hash             string   `json:"hash,omitempty"`
+	// Set is the location of the package set the package was installed from.
+	Set string `json:"set,omitempty"`
+	// Source is the directory of the Motoko sources, relative to the package.
+	// Defaults to `src`.
+	Source string `json:"source,omitempty"`
 }
 
 func (p *PackageInfoRemote) AddName(name string) {
@@ -26,27 +32,108 @@ func (p *PackageInfoRemote) AddName(name string) {
 	p.AlternativeNames = append(p.AlternativeNames, name)
 }
 
-func (p PackageInfoRemote) Download() error {
-	if err := tar.Download(
-		fmt.Sprintf(
-			"%s/archive/%s/.tar.gz",
-			strings.TrimSuffix(p.Repository, ".git"), p.Version,
-		),
-		".oko",
-	); err != nil {
+// Download downloads the package and verifies its contents. The hash of the
+// contents gets recorded if no hash was recorded yet.
+func (p *PackageInfoRemote) Download() error {
+	l, err := LockStore()
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	return p.download()
+}
+
+// Redownload removes the package from the store and downloads it again, see
+// Download.
+func (p *PackageInfoRemote) Redownload() error {
+	l, err := LockStore()
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	if err := os.RemoveAll(p.RelativePath()); err != nil {
+		return NewIOError(err)
+	}
+	return p.download()
+}
+
+// download downloads the package and verifies its contents. The lock on the
+// store should be held.
+func (p *PackageInfoRemote) download() error {
+	if _, err := Store().DownloadPackage(p.Repository, p.Version); err != nil {
 		return internal.Error(err)
 	}
-	return nil
+	if p.Hash == "" {
+		hash, err := checksum.Dir(p.RelativePath())
+		if err != nil {
+			return NewIOError(err)
+		}
+		p.Hash = hash
+		return nil
+	}
+	return p.Verify()
 }
 
 func (p PackageInfoRemote) GetName() string {
 	return p.Name
 }
 
+// RelativePath returns the directory of the package in the store.
 func (p PackageInfoRemote) RelativePath() string {
-	repo := strings.TrimSuffix(p.Repository, ".git")
-	version := strings.TrimPrefix(p.Version, "v")
-	return fmt.Sprintf(".oko/%s-%s", repo[strings.LastIndex(repo, "/")+1:], version)
+	return Store().PackageDir(p.Repository, p.Version)
+}
+
+// SourcePath returns the path to the Motoko sources of the package.
+func (p PackageInfoRemote) SourcePath() string {
+	return filepath.Join(p.RelativePath(), p.sourceDir())
+}
+
+// Verify checks whether the package is present and complete, and whether its
+// contents match the recorded hash (if any).
+func (p PackageInfoRemote) Verify() error {
+	path := p.RelativePath()
+	if _, err := os.Stat(path); err != nil {
+		return NewPackageMissingError(p.Name, path)
+	}
+	if info, err := os.Stat(p.SourcePath()); err != nil || !info.IsDir() {
+		return NewPackageIncompleteError(p.Name, p.sourceDir())
+	}
+	if p.Hash == "" {
+		return nil
+	}
+	hash, err := checksum.Dir(path)
+	if err != nil {
+		return NewIOError(err)
+	}
+	if hash != p.Hash {
+		return checksum.NewMismatchError(p.Name, p.Hash, hash)
+	}
+	return nil
+}
+
+// sourceDir returns the directory of the Motoko sources, relative to the
+// package.
+func (p PackageInfoRemote) sourceDir() string {
+	if p.Source != "" {
+		return p.Source
+	}
+	return DefaultSource
+}
+
+// update updates the repository, version and dependencies to those of the
+// given package. The recorded hash is reset if the contents changed.
+func (p *PackageInfoRemote) update(o PackageInfoRemote) {
+	if !p.equals(o) {
+		p.Hash = ""
+	}
+	p.Repository = o.Repository
+	p.Version = o.Version
+	p.Dependencies = o.Dependencies
+	p.Source = o.Source
+	if o.Set != "" {
+		p.Set = o.Set
+	}
 }
 
 // equals returns true if both the repository and version match.
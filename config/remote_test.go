@@ -0,0 +1,37 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/internet-computer/oko/config"
+)
+
+func TestPackageInfoRemote_Verify(t *testing.T) {
+	config.SetStore(t.TempDir())
+	defer config.SetStore("")
+
+	dep := config.PackageInfoRemote{
+		Name:       "lib",
+		Repository: "https://github.com/internet-computer/lib",
+		Version:    "v0.1.0",
+		Source:     "motoko",
+	}
+	var missingErr *config.PackageMissingError
+	if err := dep.Verify(); !errors.As(err, &missingErr) {
+		t.Error(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dep.RelativePath(), "motoko"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := dep.Verify(); err != nil {
+		t.Error(err)
+	}
+	dep.Source = ""
+	var incompleteErr *config.PackageIncompleteError
+	if err := dep.Verify(); !errors.As(err, &incompleteErr) {
+		t.Error(err)
+	}
+}
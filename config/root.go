@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ManifestName is the name of the Oko package file.
+const ManifestName = "oko.json"
+
+// manifest is the path to the package file, if set explicitly.
+var manifest string
+
+// SetManifest sets the path to the package file, which disables the discovery
+// of the package file in the parent directories.
+func SetManifest(path string) {
+	manifest = path
+}
+
+// ManifestPath returns the path to the package file. Unless set explicitly,
+// the package file is searched for in the working directory and its parents.
+// Returns the package file in the working directory if none was found.
+func ManifestPath() string {
+	if manifest != "" {
+		return manifest
+	}
+	if root, err := FindRoot("."); err == nil {
+		return filepath.Join(root, ManifestName)
+	}
+	return ManifestName
+}
+
+// NewManifestPath returns the path for a new package file, i.e. the explicitly
+// set path or the package file in the working directory.
+func NewManifestPath() string {
+	if manifest != "" {
+		return manifest
+	}
+	return ManifestName
+}
+
+// Root returns the root directory of the package, the directory that contains
+// the package file.
+func Root() string {
+	return filepath.Dir(ManifestPath())
+}
+
+// Path returns the given path, relative to the root directory, relative to the
+// working directory. Absolute paths are returned as is.
+func Path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(Root(), path)
+}
+
+// NewPath returns the given path, relative to the directory of a new package
+// file (see NewManifestPath), relative to the working directory. Absolute paths
+// are returned as is.
+func NewPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(NewManifestPath()), path)
+}
+
+// FindRoot returns the closest directory, starting from the given directory,
+// that contains a package file. The returned path is relative to the given
+// directory (unless absolute).
+func FindRoot(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", NewIOError(err)
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ManifestName)); err == nil && !info.IsDir() {
+			return dir, nil
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", NewManifestNotFoundError()
+		}
+		abs = parent
+		dir = filepath.Join(dir, "..")
+	}
+}
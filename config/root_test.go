@@ -0,0 +1,54 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/store"
+)
+
+func TestFindRoot(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "src", "lib")
+	if err := os.MkdirAll(nested, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	var notFound *config.ManifestNotFoundError
+	if _, err := config.FindRoot(nested); !errors.As(err, &notFound) {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, config.ManifestName), []byte("{}"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	root, err := config.FindRoot(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != dir {
+		t.Error(root)
+	}
+	if root, _ := config.FindRoot(dir); root != dir {
+		t.Error(root)
+	}
+}
+
+func TestStore(t *testing.T) {
+	t.Setenv(store.EnvHome, "")
+	if root := config.Store().Root; root != filepath.Join(config.Root(), ".oko") {
+		t.Error(root)
+	}
+	t.Setenv(store.EnvHome, "home")
+	if root := config.Store().Root; root != "home" {
+		t.Error(root)
+	}
+	config.SetStore("store")
+	defer config.SetStore("")
+	if root := config.Store().Root; root != "store" {
+		t.Error(root)
+	}
+}
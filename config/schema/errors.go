@@ -3,8 +3,6 @@ package schema
 import (
 	"fmt"
 	"strings"
-
-	"github.com/xeipuuv/gojsonschema"
 )
 
 type SchemaError struct {
@@ -24,23 +22,37 @@ func (e SchemaError) Error() string {
 	)
 }
 
+type UnsupportedVersionError struct {
+	Version int
+}
+
+func NewUnsupportedVersionError(version int) *UnsupportedVersionError {
+	return &UnsupportedVersionError{
+		Version: version,
+	}
+}
+
+func (e UnsupportedVersionError) Error() string {
+	return fmt.Sprintf(
+		"unsupported package file version %d, the latest supported version is %d",
+		e.Version, LatestVersion,
+	)
+}
+
 type ValidationError struct {
-	Errors []gojsonschema.ResultError
+	Problems []Problem
 }
 
-func NewValidationError(errors []gojsonschema.ResultError) *ValidationError {
+func NewValidationError(problems []Problem) *ValidationError {
 	return &ValidationError{
-		Errors: errors,
+		Problems: problems,
 	}
 }
 
 func (e ValidationError) Error() string {
 	var messages []string
-	for _, err := range e.Errors {
-		messages = append(messages, err.Description())
+	for _, problem := range e.Problems {
+		messages = append(messages, problem.String())
 	}
-	return fmt.Sprintf(
-		"validation error: %s",
-		strings.Join(messages, ", "),
-	)
+	return strings.Join(messages, "; ")
 }
@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Problem is a problem in a package file.
+type Problem struct {
+	// Pointer is the JSON pointer to the value that caused the problem, i.e.
+	// `/dependencies/0/name`. An empty pointer refers to the whole document.
+	Pointer string
+	// Line and Column are the location of the value (starting at 1), zero if
+	// the location is unknown.
+	Line, Column int
+	Message      string
+}
+
+// NewProblem creates a new problem at the given JSON pointer.
+func NewProblem(pointer, format string, a ...interface{}) Problem {
+	return Problem{
+		Pointer: pointer,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+func (p Problem) String() string {
+	pointer := p.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", pointer, p.Message)
+	}
+	return fmt.Sprintf("%s at %d:%d: %s", pointer, p.Line, p.Column, p.Message)
+}
+
+// Locate sets the line and column of the given problems, based on the location
+// of the values in the given raw JSON. Problems of which the value does not
+// exist (i.e. a missing property) get the location of the closest parent.
+func Locate(raw []byte, problems []Problem) []Problem {
+	l := locator{
+		raw:     raw,
+		dec:     json.NewDecoder(bytes.NewReader(raw)),
+		offsets: make(map[string]int),
+	}
+	// Ignore errors, the locations of invalid JSON are unknown.
+	_ = l.value("")
+
+	located := make([]Problem, len(problems))
+	for i, problem := range problems {
+		pointer := problem.Pointer
+		offset, ok := l.offsets[pointer]
+		for !ok && pointer != "" {
+			pointer = pointer[:strings.LastIndex(pointer, "/")]
+			offset, ok = l.offsets[pointer]
+		}
+		if ok {
+			problem.Line, problem.Column = l.position(offset)
+		}
+		located[i] = problem
+	}
+	return located
+}
+
+// locator keeps track of the offsets of all values in a JSON document.
+type locator struct {
+	raw     []byte
+	dec     *json.Decoder
+	offsets map[string]int
+}
+
+// position returns the line and column of the given offset.
+func (l locator) position(offset int) (int, int) {
+	before := l.raw[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// start returns the offset of the next value.
+func (l locator) start() int {
+	offset := int(l.dec.InputOffset())
+	for offset < len(l.raw) {
+		switch l.raw[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// value records the offset of the next value, and of all its children.
+func (l *locator) value(pointer string) error {
+	l.offsets[pointer] = l.start()
+	token, err := l.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		for l.dec.More() {
+			key, err := l.dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := l.value(pointer + "/" + escapePointer(fmt.Sprint(key))); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; l.dec.More(); i++ {
+			if err := l.value(pointer + "/" + strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	// Closing delimiter.
+	_, err = l.dec.Token()
+	return err
+}
+
+// escapePointer escapes a reference token of a JSON pointer.
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -1,27 +1,83 @@
 package schema
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/xeipuuv/gojsonschema"
 
 	_ "embed"
 )
 
-//go:embed testdata/config.schema.json
-var rawJSONSchema []byte
+// LatestVersion is the version of the current package file format.
+const LatestVersion = 2
+
+var (
+	//go:embed testdata/config.v1.schema.json
+	rawJSONSchemaV1 []byte
+	//go:embed testdata/config.schema.json
+	rawJSONSchema []byte
+)
 
-var schemaLoader gojsonschema.JSONLoader
+// schemaLoaders contains the schema of every version.
+var schemaLoaders map[int]gojsonschema.JSONLoader
 
+// Validate validates the package file against the schema of its version.
 func Validate(raw []byte) error {
+	version, err := Version(raw)
+	if err != nil {
+		return err
+	}
+	return ValidateVersion(raw, version)
+}
+
+// ValidateVersion validates the package file against the schema of the given version.
+func ValidateVersion(raw []byte, version int) error {
+	schemaLoader, ok := schemaLoaders[version]
+	if !ok {
+		return NewUnsupportedVersionError(version)
+	}
 	result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewBytesLoader(raw))
 	if err != nil {
 		return NewSchemaError(err)
 	}
 	if !result.Valid() {
-		return NewValidationError(result.Errors())
+		var problems []Problem
+		for _, err := range result.Errors() {
+			problems = append(problems, NewProblem(pointer(err.Context()), "%s", err.Description()))
+		}
+		return NewValidationError(Locate(raw, problems))
 	}
 	return nil
 }
 
+// pointer converts the given context to a JSON pointer.
+func pointer(context *gojsonschema.JsonContext) string {
+	var tokens []string
+	for _, token := range strings.Split(context.String("\x00"), "\x00")[1:] {
+		tokens = append(tokens, "/"+escapePointer(token))
+	}
+	return strings.Join(tokens, "")
+}
+
+// Version returns the version of the package file. Package files without a
+// version field are version 1.
+func Version(raw []byte) (int, error) {
+	var pkg struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &pkg); err != nil {
+		return 0, NewSchemaError(err)
+	}
+	if pkg.Version == nil {
+		return 1, nil
+	}
+	return *pkg.Version, nil
+}
+
 func init() {
-	schemaLoader = gojsonschema.NewBytesLoader(rawJSONSchema)
+	schemaLoaders = map[int]gojsonschema.JSONLoader{
+		1: gojsonschema.NewBytesLoader(rawJSONSchemaV1),
+		2: gojsonschema.NewBytesLoader(rawJSONSchema),
+	}
 }
@@ -1,6 +1,8 @@
 package schema_test
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/internet-computer/oko/config/schema"
@@ -34,3 +36,48 @@ func TestValidate(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestVersion(t *testing.T) {
+	for raw, version := range map[string]int{
+		`{ "dependencies": [] }`:               1,
+		`{ "version": 2, "dependencies": [] }`: 2,
+	} {
+		v, err := schema.Version([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != version {
+			t.Error(raw, v)
+		}
+		if err := schema.Validate([]byte(raw)); err != nil {
+			t.Error(raw, err)
+		}
+	}
+	if err := schema.ValidateVersion([]byte(`{ "dependencies": [] }`), 2); err == nil {
+		t.Error("version 2 requires a version field")
+	}
+	if err := schema.Validate([]byte(`{ "version": 3, "dependencies": [] }`)); err == nil {
+		t.Error("unsupported version")
+	}
+}
+
+func ExampleLocate() {
+	raw := []byte(`{
+	"dependencies": [
+		{
+			"name": "test",
+			"version": 1
+		}
+	]
+}`)
+	err := schema.Validate(raw)
+	var validationErr *schema.ValidationError
+	if errors.As(err, &validationErr) {
+		for _, problem := range validationErr.Problems {
+			fmt.Println(problem)
+		}
+	}
+	// Output:
+	// /dependencies/0 at 3:3: repository is required
+	// /dependencies/0/version at 5:15: Invalid type. Expected: string, given: integer
+}
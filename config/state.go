@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -13,35 +14,64 @@ import (
 
 // PackageState is the in-memory state of the packages.
 type PackageState struct {
+	Package                *PackageMetadata
+	Source                 *string
 	CompilerVersion        *string
+	DidcVersion            *string
+	Checksums              map[string]string
+	PackageSet             *string
 	Dependencies           map[string]*PackageInfoRemote
 	LocalDependencies      map[string]*PackageInfoLocal
 	TransitiveDependencies map[string]*PackageInfoRemote
+	Targets                map[string]BuildTarget
+	Scripts                map[string]string
+
+	// Warnings are the (located) problems of the package file the state was
+	// loaded from, that do not prevent it from being used, e.g. unresolved
+	// dependencies or missing local paths.
+	Warnings []schema.Problem
+
+	// raw is the package file the state was loaded from, if any. Used to
+	// preserve its formatting and unknown keys.
+	raw []byte
 }
 
 // EmptyState returns an empty package state.
 func EmptyState() PackageState {
 	return PackageState{
+		Checksums:              make(map[string]string),
 		Dependencies:           make(map[string]*PackageInfoRemote),
 		LocalDependencies:      make(map[string]*PackageInfoLocal),
 		TransitiveDependencies: make(map[string]*PackageInfoRemote),
+		Targets:                make(map[string]BuildTarget),
+		Scripts:                make(map[string]string),
 	}
 }
 
-// LoadPackageState loads a package config file.
+// LoadPackageState loads a package config file. Older versions of the package
+// file get upgraded to the latest version. Returns a validation error if the
+// package file does not match the schema, or uses a name more than once. Other
+// problems are recorded in the warnings of the state.
 func LoadPackageState(path string) (*PackageState, error) {
 	raw, err := os.ReadFile(path)
 	if err != nil {
 		return nil, NewIOError(err)
 	}
-	if err := schema.Validate(raw); err != nil {
-		return nil, NewValidationError(err)
+	upgraded, _, err := Migrate(raw)
+	if err != nil {
+		return nil, err
 	}
-	pkg, err := NewPackageConfig(raw)
+	pkg, err := NewPackageConfig(upgraded)
 	if err != nil {
 		return nil, err
 	}
-	return NewPackageState(pkg), nil
+	if problems := pkg.problems(); len(problems) != 0 {
+		return nil, NewValidationError(schema.NewValidationError(schema.Locate(raw, problems)))
+	}
+	state := NewPackageState(pkg)
+	state.Warnings = schema.Locate(raw, pkg.warnings(filepath.Dir(path)))
+	state.raw = raw
+	return state, nil
 }
 
 // NewPackageState creates a new package state based on the given package config.
@@ -50,7 +80,14 @@ func NewPackageState(pkg *PackageConfig) *PackageState {
 	if pkg == nil {
 		return &state
 	}
+	state.Package = pkg.Package
+	state.Source = pkg.Source
 	state.CompilerVersion = pkg.CompilerVersion
+	state.DidcVersion = pkg.DidcVersion
+	state.PackageSet = pkg.PackageSet
+	for url, sum := range pkg.Checksums {
+		state.Checksums[url] = sum
+	}
 	for _, dep := range pkg.Dependencies {
 		d := dep // copy
 		state.Dependencies[dep.Name] = &d
@@ -63,6 +100,12 @@ func NewPackageState(pkg *PackageConfig) *PackageState {
 		d := dep // copy
 		state.TransitiveDependencies[dep.Name] = &d
 	}
+	for name, target := range pkg.Targets {
+		state.Targets[name] = target
+	}
+	for name, script := range pkg.Scripts {
+		state.Scripts[name] = script
+	}
 	return &state
 }
 
@@ -152,6 +195,28 @@ func (s PackageState) Get(p PackageInfoRemote) (*PackageInfoRemote, bool, error)
 	return nil, false, nil
 }
 
+// GetByName returns the (transitive) package that matches the given name.
+func (s PackageState) GetByName(name string) *PackageInfoRemote {
+	if dep := s.getDependencyByName(name); dep != nil {
+		return dep
+	}
+	return s.getTransitiveDependencyByName(name)
+}
+
+// GetDependents returns the names of all packages that depend on the given package.
+func (s PackageState) GetDependents(pkg PackageInfoRemote) []string {
+	var dependents []string
+	for _, dep := range append(s.dependencyList(), s.transitiveDependencyList()...) {
+		for _, name := range dep.Dependencies {
+			if pkg.hasName(name) {
+				dependents = append(dependents, dep.Name)
+				break
+			}
+		}
+	}
+	return dependents
+}
+
 // Get returns the package matching the given package info.
 // Returns an error if a package with the same name already exists.
 func (s PackageState) GetLocal(p PackageInfoLocal) (*PackageInfoLocal, error) {
@@ -201,29 +266,120 @@ func (s PackageState) GetTransitive(p PackageInfoRemote) (*PackageInfoRemote, bo
 	return nil, false, nil
 }
 
-// LoadState loads in another package state.
-func (s PackageState) LoadState(state *PackageState) error {
-	for _, dep := range state.Dependencies {
-		dependencies, err := s.GetPackageDependencies(dep)
-		if err != nil {
+// LocalPath returns the path of the given local package, relative to the
+// working directory. The paths of local packages that are declared by a remote
+// package are relative to the directory of that package.
+func (s PackageState) LocalPath(dep PackageInfoLocal) string {
+	if dep.Parent != "" {
+		if parent := s.GetByName(dep.Parent); parent != nil {
+			return filepath.Join(parent.RelativePath(), filepath.FromSlash(dep.Path))
+		}
+	}
+	return dep.RelativePath()
+}
+
+// LoadState loads in another package state. If a parent is given, the other
+// state is the state of that package: the parent gets added as a dependency,
+// which depends on the direct dependencies of the other state, and all remote
+// packages of the other state get added as transitive dependencies. The source
+// directory of the parent is taken from the other state. The local packages of
+// the other state are resolved relative to the directory of the parent, or
+// relative to the root directory if no parent is given. Local packages that
+// already have a parent in the other state keep it.
+func (s PackageState) LoadState(state *PackageState, parent *PackageInfoRemote) error {
+	var locals []PackageInfoLocal
+	for _, dep := range state.localDependencyList() {
+		if parent != nil {
+			path, ok := packagePath(dep.Path)
+			if !ok {
+				return NewLocalPathOutsideError(parent.Name, dep.Path)
+			}
+			dep.Path = path
+			if dep.Parent == "" {
+				dep.Parent = parent.Name
+			}
+		}
+		locals = append(locals, dep)
+	}
+
+	if parent != nil {
+		pkg := *parent // copy
+		if state.Source != nil {
+			source, ok := packagePath(*state.Source)
+			if !ok {
+				return NewSourceOutsideError(parent.Name, *state.Source)
+			}
+			pkg.Source = source
+		}
+		pkg.Dependencies = nil
+		for _, dep := range state.dependencyList() {
+			pkg.Dependencies = append(pkg.Dependencies, dep.Name)
+		}
+		for _, dep := range locals {
+			if dep.Parent == parent.Name {
+				pkg.Dependencies = append(pkg.Dependencies, dep.Name)
+			}
+		}
+		if err := s.loadPackage(pkg, append(state.dependencyList(), state.transitiveDependencyList()...)...); err != nil {
 			return err
 		}
-		if err := s.AddPackage(*dep, dependencies...); err != nil {
+	} else {
+		for _, dep := range state.Dependencies {
+			dependencies, err := s.GetPackageDependencies(dep)
+			if err != nil {
+				return err
+			}
+			if err := s.loadPackage(*dep, dependencies...); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, dep := range locals {
+		if p, err := s.GetLocal(dep); err == nil && p != nil {
+			// Already loaded.
+			continue
+		}
+		if err := s.AddLocalPackage(dep); err != nil {
 			return err
 		}
 	}
-	// TODO: local packages!
 	return nil
 }
 
-// MarshalJSON converts the state to raw (formatted) JSON.
+// loadPackage adds the given package and its dependencies, like AddPackage.
+// If the package was already loaded, its dependencies and source directory get
+// updated instead, so that loading the same state twice is fine.
+func (s *PackageState) loadPackage(pkg PackageInfoRemote, dependencies ...PackageInfoRemote) error {
+	if p, same, err := s.Get(pkg); err == nil && p != nil && same {
+		p.Dependencies = pkg.Dependencies
+		p.Source = pkg.Source
+		return s.addPackageDependencies(dependencies...)
+	}
+	return s.AddPackage(pkg, dependencies...)
+}
+
+// MarshalJSON converts the state to raw (formatted) JSON. If the state was
+// loaded from a package file, its formatting and unknown keys are preserved.
 func (s PackageState) MarshalJSON() ([]byte, error) {
-	raw, err := json.MarshalIndent(PackageConfig{
+	pkg := PackageConfig{
+		Version:                schema.LatestVersion,
+		Package:                s.Package,
+		Source:                 s.Source,
 		CompilerVersion:        s.CompilerVersion,
+		DidcVersion:            s.DidcVersion,
+		Checksums:              s.Checksums,
+		PackageSet:             s.PackageSet,
 		Dependencies:           s.dependencyList(),
 		LocalDependencies:      s.localDependencyList(),
 		TransitiveDependencies: s.transitiveDependencyList(),
-	}, "", "\t")
+		Targets:                s.Targets,
+		Scripts:                s.Scripts,
+	}
+	if s.raw != nil {
+		return merge(s.raw, pkg)
+	}
+	raw, err := json.MarshalIndent(pkg, "", "\t")
 	if err != nil {
 		return nil, internal.Error(err)
 	}
@@ -287,11 +443,13 @@ func (s *PackageState) RemovePackage(name string) error {
 	// No alternative names, can be safely removed.
 	if len(pkg.AlternativeNames) == 0 {
 		delete(s.Dependencies, name)
+		s.removeNestedLocalPackages(name)
 		return nil
 	}
 
 	// Remove the current name, but do not remove the package.
 	if pkg.Name == name {
+		s.renameParent(name, pkg.AlternativeNames[0])
 		pkg.Name = pkg.AlternativeNames[0]
 		pkg.AlternativeNames = pkg.AlternativeNames[1:]
 		return nil
@@ -309,18 +467,64 @@ func (s *PackageState) RemovePackage(name string) error {
 	return NewPackageNotFoundError(name)
 }
 
-// Save writes the state to the given path.
+// Save atomically writes the state to the given path.
 func (s PackageState) Save(path string) error {
 	json, err := s.MarshalJSON()
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(path, json, os.ModePerm); err != nil {
+	if err := writeFile(path, json); err != nil {
 		return NewIOError(err)
 	}
 	return nil
 }
 
+// UpdatePackage updates the package with the same name, and its dependencies,
+// to the given versions. Only dependencies that were installed from the same
+// package set get updated, others are left as is. Dependencies that do not
+// exist yet are added to the transitive package list.
+func (s *PackageState) UpdatePackage(pkg PackageInfoRemote, dependencies ...PackageInfoRemote) error {
+	p := s.GetByName(pkg.Name)
+	if p == nil {
+		return NewPackageNotFoundError(pkg.Name)
+	}
+	p.update(pkg)
+
+	for _, dep := range dependencies {
+		if d := s.GetByName(dep.Name); d != nil {
+			if d.Set == dep.Set {
+				d.update(dep)
+			}
+			continue
+		}
+		if err := s.addPackageDependencies(dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnresolvedDependencies returns all dependencies that are not provided by any
+// (transitive or local) package, sorted by package name.
+func (s PackageState) UnresolvedDependencies() []*UnresolvedDependencyError {
+	var unresolved []*UnresolvedDependencyError
+	for _, pkg := range append(s.dependencyList(), s.transitiveDependencyList()...) {
+		for _, name := range pkg.Dependencies {
+			if s.getDependencyByName(name) != nil {
+				continue
+			}
+			if s.getTransitiveDependencyByName(name) != nil {
+				continue
+			}
+			if _, ok := s.LocalDependencies[name]; ok {
+				continue
+			}
+			unresolved = append(unresolved, NewUnresolvedDependencyError(pkg.Name, name))
+		}
+	}
+	return unresolved
+}
+
 // addPackageDependencies adds the given packages to the transitive package list.
 func (s *PackageState) addPackageDependencies(dependencies ...PackageInfoRemote) error {
 	for _, dep := range dependencies {
@@ -427,6 +631,16 @@ func (s PackageState) getPackageDependencies(info *PackageInfoRemote) (map[strin
 	return dependencies, nil
 }
 
+// getTransitiveDependencyByName return the transitive package that matches the given name.
+func (s PackageState) getTransitiveDependencyByName(name string) *PackageInfoRemote {
+	for _, dep := range s.TransitiveDependencies {
+		if dep.hasName(name) {
+			return dep
+		}
+	}
+	return nil
+}
+
 // LocalDependencyList returns a sorted list of local dependencies.
 func (s PackageState) localDependencyList() []PackageInfoLocal {
 	var dependencies []PackageInfoLocal
@@ -439,6 +653,37 @@ func (s PackageState) localDependencyList() []PackageInfoLocal {
 	return dependencies
 }
 
+// packagePath cleans the given (slash separated) path, relative to the
+// directory of a package. Returns false if the path is outside of the package,
+// published packages can only refer to their own files.
+func packagePath(path string) (string, bool) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(clean), true
+}
+
+// removeNestedLocalPackages removes the local packages declared by the remote
+// package with the given name.
+func (s *PackageState) removeNestedLocalPackages(parent string) {
+	for name, dep := range s.LocalDependencies {
+		if dep.Parent == parent {
+			delete(s.LocalDependencies, name)
+		}
+	}
+}
+
+// renameParent updates the parent of the local packages declared by the remote
+// package that gets renamed.
+func (s *PackageState) renameParent(old, new string) {
+	for _, dep := range s.LocalDependencies {
+		if dep.Parent == old {
+			dep.Parent = new
+		}
+	}
+}
+
 // removeTransitivePackage removes transitive dependencies if they are not in use.
 func (s *PackageState) removeTransitivePackage(name string) error {
 	var pkg *PackageInfoRemote
@@ -476,11 +721,13 @@ func (s *PackageState) removeTransitivePackage(name string) error {
 	// No alternative names, can be safely removed.
 	if len(pkg.AlternativeNames) == 0 {
 		delete(s.TransitiveDependencies, name)
+		s.removeNestedLocalPackages(name)
 		return nil
 	}
 
 	// Remove the current name, but do not remove the package.
 	if pkg.Name == name {
+		s.renameParent(name, pkg.AlternativeNames[0])
 		pkg.Name = pkg.AlternativeNames[0]
 		pkg.AlternativeNames = pkg.AlternativeNames[1:]
 		return nil
@@ -1,10 +1,15 @@
 package config_test
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/internet-computer/oko/config"
+	"github.com/internet-computer/oko/config/schema"
 )
 
 func ExamplePackageState() {
@@ -12,6 +17,7 @@ func ExamplePackageState() {
 	fmt.Println(string(json))
 	// Output:
 	// {
+	// 	"version": 2,
 	//	"dependencies": []
 	// }
 }
@@ -27,6 +33,7 @@ func ExamplePackageState_AddPackage() {
 	fmt.Println(string(json))
 	// Output:
 	// {
+	// 	"version": 2,
 	// 	"dependencies": [
 	// 		{
 	// 			"name": "test",
@@ -52,6 +59,7 @@ func ExamplePackageState_AddPackage_alreadyExits() {
 	fmt.Println(string(json))
 	// Output:
 	// {
+	// 	"version": 2,
 	// 	"dependencies": [
 	// 		{
 	// 			"name": "",
@@ -90,6 +98,7 @@ func ExamplePackageState_AddPackage_fromTransitive() {
 	fmt.Println(string(json))
 	// Output:
 	// {
+	// 	"version": 2,
 	// 	"dependencies": [
 	// 		{
 	// 			"name": "test-v0.1.0",
@@ -109,6 +118,7 @@ func ExamplePackageState_AddPackage_fromTransitive() {
 	// 	]
 	// }
 	// {
+	// 	"version": 2,
 	// 	"dependencies": [
 	// 		{
 	// 			"name": "test",
@@ -135,11 +145,12 @@ func ExamplePackageState_LoadState() {
 		Version:    "*",
 	})
 	other := config.EmptyState()
-	_ = state.LoadState(&other)
+	_ = state.LoadState(&other, nil)
 	json, _ := state.MarshalJSON()
 	fmt.Println(string(json))
 	// Output:
 	// {
+	// 	"version": 2,
 	// 	"dependencies": [
 	// 		{
 	// 			"name": "test",
@@ -162,10 +173,73 @@ func ExamplePackageState_RemovePackage() {
 	fmt.Println(string(json))
 	// Output:
 	// {
+	// 	"version": 2,
 	//	"dependencies": []
 	// }
 }
 
+func ExamplePackageState_UnresolvedDependencies() {
+	state := config.EmptyState()
+	_ = state.AddPackage(config.PackageInfoRemote{
+		Name:         "test",
+		Repository:   "url",
+		Version:      "*",
+		Dependencies: []string{"base", "local"},
+	})
+	_ = state.AddLocalPackage(config.PackageInfoLocal{
+		Name: "local",
+		Path: "src",
+	})
+	for _, err := range state.UnresolvedDependencies() {
+		fmt.Println(err)
+	}
+	// Output:
+	// package "test" depends on "base", which is not provided by any package
+}
+
+func ExamplePackageState_UpdatePackage() {
+	state := config.EmptyState()
+	_ = state.AddPackage(config.PackageInfoRemote{
+		Name:       "test",
+		Repository: "url",
+		Version:    "v0.1.0",
+		Hash:       "0000000000000000000000000000000000000000000000000000000000000000",
+	})
+	_ = state.UpdatePackage(config.PackageInfoRemote{
+		Name:         "test",
+		Repository:   "url",
+		Version:      "v0.2.0",
+		Dependencies: []string{"base"},
+	}, config.PackageInfoRemote{
+		Name:       "base",
+		Repository: "base",
+		Version:    "*",
+	})
+	json, _ := state.MarshalJSON()
+	fmt.Println(string(json))
+	// Output:
+	// {
+	// 	"version": 2,
+	// 	"dependencies": [
+	// 		{
+	// 			"name": "test",
+	// 			"repository": "url",
+	// 			"version": "v0.2.0",
+	// 			"dependencies": [
+	// 				"base"
+	// 			]
+	// 		}
+	// 	],
+	// 	"transitiveDependencies": [
+	// 		{
+	// 			"name": "base",
+	// 			"repository": "base",
+	// 			"version": "*"
+	// 		}
+	// 	]
+	// }
+}
+
 func TestPackageState_AddPackage(t *testing.T) {
 	pkg := config.EmptyState()
 	dep := config.PackageInfoRemote{
@@ -186,6 +260,118 @@ func TestPackageState_AddPackage(t *testing.T) {
 	}
 }
 
+func TestPackageState_LoadState_local(t *testing.T) {
+	lib := config.PackageInfoRemote{
+		Name:       "lib",
+		Repository: "https://github.com/internet-computer/lib",
+		Version:    "v0.1.0",
+	}
+	state := config.EmptyState()
+	other := config.NewPackageState(&config.PackageConfig{
+		LocalDependencies: []config.PackageInfoLocal{
+			{Name: "util", Path: "./util"},
+		},
+	})
+	if err := state.LoadState(other, &lib); err != nil {
+		t.Fatal(err)
+	}
+	expected := config.PackageInfoLocal{Name: "util", Path: "util", Parent: "lib"}
+	if util := state.LocalDependencies["util"]; util == nil || *util != expected {
+		t.Fatal(util)
+	}
+	if path := state.LocalPath(*state.LocalDependencies["util"]); path != filepath.Join(lib.RelativePath(), "util") {
+		t.Error(path)
+	}
+	if dep := state.Dependencies["lib"]; dep == nil || !reflect.DeepEqual(dep.Dependencies, []string{"util"}) {
+		t.Error(dep)
+	}
+	// Loading the same state twice is fine.
+	if err := state.LoadState(other, &lib); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Dependencies) != 1 || len(state.LocalDependencies) != 1 {
+		t.Error(state.Dependencies, state.LocalDependencies)
+	}
+
+	for _, path := range []string{"../util", "util/../..", "/util"} {
+		outside := config.NewPackageState(&config.PackageConfig{
+			LocalDependencies: []config.PackageInfoLocal{
+				{Name: "outside", Path: path},
+			},
+		})
+		var outsideErr *config.LocalPathOutsideError
+		if err := state.LoadState(outside, &lib); !errors.As(err, &outsideErr) {
+			t.Error(path, err)
+		}
+	}
+
+	// Nested local packages are removed with their parent.
+	if err := state.RemovePackage("lib"); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.LocalDependencies) != 0 {
+		t.Error(state.LocalDependencies)
+	}
+}
+
+func TestPackageState_LoadState_nestedLocal(t *testing.T) {
+	lib := config.PackageInfoRemote{
+		Name:       "lib",
+		Repository: "https://github.com/org/lib",
+		Version:    "v1",
+	}
+	base := config.PackageInfoRemote{
+		Name:         "base",
+		Repository:   "https://github.com/org/base",
+		Version:      "v1",
+		Dependencies: []string{"x"},
+	}
+	state := config.EmptyState()
+	other := config.NewPackageState(&config.PackageConfig{
+		Dependencies: []config.PackageInfoRemote{base},
+		LocalDependencies: []config.PackageInfoLocal{
+			{Name: "x", Path: "src/x", Parent: "base"},
+		},
+	})
+	if err := state.LoadState(other, &lib); err != nil {
+		t.Fatal(err)
+	}
+	expected := config.PackageInfoLocal{Name: "x", Path: "src/x", Parent: "base"}
+	if x := state.LocalDependencies["x"]; x == nil || *x != expected {
+		t.Fatal(x)
+	}
+	if path := state.LocalPath(*state.LocalDependencies["x"]); path != filepath.Join(base.RelativePath(), "src", "x") {
+		t.Error(path)
+	}
+	if dep := state.Dependencies["lib"]; dep == nil || !reflect.DeepEqual(dep.Dependencies, []string{"base"}) {
+		t.Error(dep)
+	}
+}
+
+func TestPackageState_LoadState_source(t *testing.T) {
+	lib := config.PackageInfoRemote{
+		Name:       "lib",
+		Repository: "https://github.com/internet-computer/lib",
+		Version:    "v0.1.0",
+	}
+	for _, source := range []string{"../../..", "/src"} {
+		state := config.EmptyState()
+		var outsideErr *config.SourceOutsideError
+		if err := state.LoadState(config.NewPackageState(&config.PackageConfig{Source: &source}), &lib); !errors.As(err, &outsideErr) {
+			t.Error(source, err)
+		}
+	}
+
+	state := config.EmptyState()
+	source := "./motoko/"
+	if err := state.LoadState(config.NewPackageState(&config.PackageConfig{Source: &source}), &lib); err != nil {
+		t.Fatal(err)
+	}
+	if dep := state.Dependencies["lib"]; dep == nil || dep.SourcePath() != filepath.Join(lib.RelativePath(), "motoko") {
+		t.Error(dep)
+	}
+}
+
 func TestPackageState_RemovePackage_otherDependency(t *testing.T) {
 	state := config.EmptyState()
 	dep := config.PackageInfoRemote{
@@ -215,3 +401,180 @@ func TestPackageState_RemovePackage_otherDependency(t *testing.T) {
 		t.Error(state.Dependencies, state.TransitiveDependencies)
 	}
 }
+
+func TestPackageState_Save_preserve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), config.ManifestName)
+	raw := `{
+  "x-tool": {"a":  1},
+  "dependencies": [
+    {
+      "name": "base",
+      "repository": "https://github.com/dfinity/motoko-base",
+      "version": "moc-0.7.4"
+    }
+  ],
+  "scripts": { "hello": "echo hello" }
+}
+`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.AddPackage(config.PackageInfoRemote{
+		Name:       "lib",
+		Repository: "https://github.com/internet-computer/lib",
+		Version:    "v0.1.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	compiler := "0.7.4"
+	state.CompilerVersion = &compiler
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := os.ReadFile(path)
+	if expected := `{
+  "version": 2,
+  "compiler": "0.7.4",
+  "x-tool": {"a":  1},
+  "dependencies": [
+    {
+      "name": "base",
+      "repository": "https://github.com/dfinity/motoko-base",
+      "version": "moc-0.7.4"
+    },
+    {
+      "name": "lib",
+      "repository": "https://github.com/internet-computer/lib",
+      "version": "v0.1.0"
+    }
+  ],
+  "scripts": { "hello": "echo hello" }
+}
+`; string(saved) != expected {
+		t.Error(string(saved))
+	}
+
+	// Saving without changes does not modify the package file.
+	state, err = config.LoadPackageState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if resaved, _ := os.ReadFile(path); string(resaved) != string(saved) {
+		t.Error(string(resaved))
+	}
+}
+
+func TestPackageState_Save(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, config.ManifestName)
+	state := config.NewPackageState(&config.PackageConfig{})
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o111 != 0 {
+		t.Error(info.Mode())
+	}
+
+	// Permissions of existing files are preserved.
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Error(info.Mode())
+	}
+
+	// No temporary files are left behind.
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Error(entries)
+	}
+}
+
+func TestLoadPackageState_problems(t *testing.T) {
+	path := fmt.Sprintf("%s/oko.json", t.TempDir())
+	if err := os.WriteFile(path, []byte(`{
+	"version": 2,
+	"dependencies": [
+		{
+			"name": "test",
+			"alts": [ "base" ],
+			"repository": "url",
+			"version": "*",
+			"dependencies": [ "missing" ]
+		}
+	],
+	"localDependencies": [
+		{
+			"name": "base",
+			"path": "does/not/exist"
+		}
+	]
+}`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	_, err := config.LoadPackageState(path)
+	var validationErr *schema.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatal(err)
+	}
+	var problems []string
+	for _, problem := range validationErr.Problems {
+		problems = append(problems, problem.String())
+	}
+	if expected := []string{
+		`/localDependencies/0/name at 14:12: duplicate package name "base", also used at /dependencies/0/alts/0`,
+	}; !reflect.DeepEqual(problems, expected) {
+		t.Error(problems)
+	}
+
+	// Missing paths and unresolved dependencies can still be fixed.
+	if err := os.WriteFile(path, []byte(`{
+	"version": 2,
+	"dependencies": [
+		{
+			"name": "test",
+			"repository": "url",
+			"version": "*",
+			"dependencies": [ "missing" ]
+		}
+	],
+	"localDependencies": [
+		{
+			"name": "base",
+			"path": "does/not/exist"
+		}
+	]
+}`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	state, err := config.LoadPackageState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warnings []string
+	for _, warning := range state.Warnings {
+		warnings = append(warnings, warning.String())
+	}
+	if expected := []string{
+		`/dependencies/0/dependencies/0 at 8:22: package "missing" is not provided by any package`,
+		`/localDependencies/0/path at 14:12: local package path "does/not/exist" not found`,
+	}; !reflect.DeepEqual(warnings, expected) {
+		t.Error(warnings)
+	}
+	if err := state.RemoveLocalPackage("base"); err != nil {
+		t.Error(err)
+	}
+}
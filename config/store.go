@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+
+	"github.com/internet-computer/oko/internal/lock"
+	"github.com/internet-computer/oko/store"
+)
+
+// storeRoot is the root directory of the store, if set explicitly.
+var storeRoot string
+
+// SetStore sets the root directory of the store, which takes precedence over
+// `$OKO_HOME`.
+func SetStore(root string) {
+	storeRoot = root
+}
+
+// Store returns the store that contains the downloaded packages. The root of
+// the store is the explicitly set directory, `$OKO_HOME`, or the `.oko`
+// directory in the root directory, in that order.
+func Store() store.Store {
+	if storeRoot != "" {
+		return store.New(storeRoot)
+	}
+	if home := os.Getenv(store.EnvHome); home != "" {
+		return store.New(home)
+	}
+	return store.New(Path(".oko"))
+}
+
+// LockStore acquires the lock on the store and registers the package file as a
+// user of the store, so that cleaning the store on behalf of another package
+// file does not remove its packages.
+func LockStore() (*lock.Lock, error) {
+	s := Store()
+	l, err := s.Lock()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Register(ManifestPath()); err != nil {
+		_ = l.Unlock()
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,11 @@
+package config
+
+// BuildTarget is a named build target.
+type BuildTarget struct {
+	// The entry file of the target.
+	Entry string `json:"entry"`
+	// The output wasm file.
+	Output string `json:"output,omitempty"`
+	// Additional flags passed to the compiler.
+	Flags []string `json:"flags,omitempty"`
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/internet-computer/oko/config/schema"
+)
+
+// problems returns the problems of the package config that can not be
+// expressed in the JSON schema, i.e. names that are used by more than one
+// package (including alternative names). Problems that do not make the package
+// file ambiguous are warnings instead, so that they can still be fixed with the
+// commands.
+func (pkg PackageConfig) problems() []schema.Problem {
+	var (
+		problems []schema.Problem
+		names    = make(map[string]string) // name -> pointer
+	)
+	addName := func(name, pointer string) {
+		if other, ok := names[name]; ok {
+			problems = append(problems, schema.NewProblem(pointer, "duplicate package name %q, also used at %s", name, other))
+			return
+		}
+		names[name] = pointer
+	}
+	for _, list := range []struct {
+		pointer  string
+		packages []PackageInfoRemote
+	}{
+		{"/dependencies", pkg.Dependencies},
+		{"/transitiveDependencies", pkg.TransitiveDependencies},
+	} {
+		for i, dep := range list.packages {
+			pointer := fmt.Sprintf("%s/%d", list.pointer, i)
+			addName(dep.Name, pointer+"/name")
+			for j, name := range dep.AlternativeNames {
+				addName(name, fmt.Sprintf("%s/alts/%d", pointer, j))
+			}
+		}
+	}
+	for i, dep := range pkg.LocalDependencies {
+		addName(dep.Name, fmt.Sprintf("/localDependencies/%d/name", i))
+	}
+	return problems
+}
+
+// warnings returns the problems of the package config that do not prevent it
+// from being used. Local paths are relative to the given root:
+//   - dependencies that are not provided by any package,
+//   - local packages of which the path does not exist,
+//   - local packages declared by a remote package that does not exist.
+func (pkg PackageConfig) warnings(root string) []schema.Problem {
+	var (
+		warnings []schema.Problem
+		names    = make(map[string]bool)
+		remotes  = make(map[string]bool)
+	)
+	for _, dep := range append(pkg.Dependencies, pkg.TransitiveDependencies...) {
+		names[dep.Name] = true
+		remotes[dep.Name] = true
+		for _, name := range dep.AlternativeNames {
+			names[name] = true
+		}
+	}
+	for _, dep := range pkg.LocalDependencies {
+		names[dep.Name] = true
+	}
+
+	for _, list := range []struct {
+		pointer  string
+		packages []PackageInfoRemote
+	}{
+		{"/dependencies", pkg.Dependencies},
+		{"/transitiveDependencies", pkg.TransitiveDependencies},
+	} {
+		for i, dep := range list.packages {
+			for j, name := range dep.Dependencies {
+				if !names[name] {
+					warnings = append(warnings, schema.NewProblem(
+						fmt.Sprintf("%s/%d/dependencies/%d", list.pointer, i, j),
+						"package %q is not provided by any package", name,
+					))
+				}
+			}
+		}
+	}
+	for i, dep := range pkg.LocalDependencies {
+		pointer := fmt.Sprintf("/localDependencies/%d", i)
+		if dep.Parent != "" {
+			// The path only exists once the parent is downloaded.
+			if !remotes[dep.Parent] {
+				warnings = append(warnings, schema.NewProblem(pointer+"/parent", "parent package %q not found", dep.Parent))
+			}
+			continue
+		}
+		path := filepath.FromSlash(dep.Path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			warnings = append(warnings, schema.NewProblem(pointer+"/path", "local package path %q not found", dep.Path))
+		}
+	}
+	return warnings
+}
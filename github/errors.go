@@ -2,6 +2,20 @@ package github
 
 import "fmt"
 
+type GitHubError struct {
+	Err error
+}
+
+func NewGitHubError(err error) *GitHubError {
+	return &GitHubError{
+		Err: err,
+	}
+}
+
+func (e GitHubError) Error() string {
+	return fmt.Sprintf("github error: %s", e.Err)
+}
+
 type ReleasesNotFoundErrors struct {
 	URL string
 }
@@ -15,3 +29,17 @@ func NewReleasesNotFoundErrors(url string) *ReleasesNotFoundErrors {
 func (e ReleasesNotFoundErrors) Error() string {
 	return fmt.Sprintf("no releases found for %q", e.URL)
 }
+
+type UnexpectedStatusCodeError struct {
+	StatusCode int
+}
+
+func NewUnexpectedStatusCodeError(statusCode int) *UnexpectedStatusCodeError {
+	return &UnexpectedStatusCodeError{
+		StatusCode: statusCode,
+	}
+}
+
+func (e UnexpectedStatusCodeError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
@@ -0,0 +1,44 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Example: https://api.github.com/rate_limit
+type RateLimit struct {
+	Limit     int   `json:"limit"`
+	Remaining int   `json:"remaining"`
+	Reset     int64 `json:"reset"`
+}
+
+// GetRateLimit returns the current rate limit of the GitHub API.
+func GetRateLimit() (*RateLimit, error) {
+	resp, err := Client.Get(fmt.Sprintf("%s/rate_limit", APIURL))
+	if err != nil {
+		return nil, NewGitHubError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewUnexpectedStatusCodeError(resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewGitHubError(err)
+	}
+	var limits struct {
+		Rate RateLimit `json:"rate"`
+	}
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return nil, NewGitHubError(err)
+	}
+	return &limits.Rate, nil
+}
+
+// ResetTime returns the time at which the rate limit resets.
+func (r RateLimit) ResetTime() time.Time {
+	return time.Unix(r.Reset, 0)
+}
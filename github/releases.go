@@ -1,6 +1,57 @@
 package github
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// URL is the base URL of the GitHub repositories.
+var URL = "https://github.com"
+
+// APIURL is the base URL of the GitHub API.
+var APIURL = "https://api.github.com"
+
+// Client is the HTTP client used to call the GitHub API.
+var Client = http.DefaultClient
+
+// Releases returns the releases of the given repository.
+// Expects `{org}/{repo}`.
+func Releases(repo string) ([]Release, error) {
+	resp, err := Client.Get(fmt.Sprintf("%s/repos/%s/releases", APIURL, repo))
+	if err != nil {
+		return nil, NewGitHubError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewUnexpectedStatusCodeError(resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewGitHubError(err)
+	}
+	var releases []Release
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, NewGitHubError(err)
+	}
+	return releases, nil
+}
+
 // Example: https://api.github.com/repos/internet-computer/testing.mo/releases
 type Release struct {
 	TagName string `json:"tag_name"`
 }
+
+// LatestRelease returns the latest release of the given repository.
+// Expects `{org}/{repo}`.
+func LatestRelease(repo string) (*Release, error) {
+	releases, err := Releases(repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, NewReleasesNotFoundErrors(repo)
+	}
+	return &releases[0], nil
+}
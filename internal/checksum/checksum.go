@@ -0,0 +1,71 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dir returns the hex encoded SHA-256 checksum of the contents of the given
+// directory. The checksum covers the (relative) paths and the contents of all
+// regular files in the directory.
+func Dir(path string) (string, error) {
+	h := sha256.New()
+	if err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%s\n", filepath.ToSlash(rel), Sum(raw))
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Parse parses a published checksum file (e.g. `sha256sum` output).
+// Only the first field of the file is considered.
+func Parse(raw []byte) (string, error) {
+	fields := strings.Fields(string(raw))
+	if len(fields) == 0 {
+		return "", NewInvalidChecksumError("")
+	}
+	sum := strings.ToLower(fields[0])
+	if len(sum) != sha256.Size*2 {
+		return "", NewInvalidChecksumError(fields[0])
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return "", NewInvalidChecksumError(fields[0])
+	}
+	return sum, nil
+}
+
+// Sum returns the hex encoded SHA-256 checksum of the given data.
+func Sum(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify returns an error if the checksum of the given data does not match the
+// expected checksum.
+func Verify(name string, raw []byte, expected string) error {
+	if actual := Sum(raw); !strings.EqualFold(actual, expected) {
+		return NewMismatchError(name, expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package checksum_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/internet-computer/oko/internal/checksum"
+)
+
+func ExampleSum() {
+	fmt.Println(checksum.Sum([]byte("oko")))
+	// Output:
+	// 0b4c62acca23587fe72daa1b3e1ffd0fd404195c18648a2738a65576b46b2ddc
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "src"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "lib.mo"), []byte("module {}"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := checksum.Dir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := checksum.Dir(dir); s != sum {
+		t.Errorf("expected %s, got %s", sum, s)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "lib.mo"), []byte("module { }"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := checksum.Dir(dir); s == sum {
+		t.Error("expected different checksum after modification")
+	}
+}
+
+func TestParse(t *testing.T) {
+	sum := checksum.Sum([]byte("oko"))
+	for _, raw := range []string{
+		sum,
+		fmt.Sprintf("%s  motoko-linux64-0.7.6.tar.gz\n", sum),
+	} {
+		s, err := checksum.Parse([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != sum {
+			t.Errorf("expected %s, got %s", sum, s)
+		}
+	}
+	for _, raw := range []string{"", "abc", "Not Found"} {
+		if _, err := checksum.Parse([]byte(raw)); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	raw := []byte("oko")
+	if err := checksum.Verify("oko", raw, checksum.Sum(raw)); err != nil {
+		t.Error(err)
+	}
+	if err := checksum.Verify("oko", raw, checksum.Sum([]byte("moc"))); err == nil {
+		t.Error()
+	}
+}
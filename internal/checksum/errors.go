@@ -0,0 +1,38 @@
+package checksum
+
+import "fmt"
+
+type InvalidChecksumError struct {
+	Checksum string
+}
+
+func NewInvalidChecksumError(checksum string) *InvalidChecksumError {
+	return &InvalidChecksumError{
+		Checksum: checksum,
+	}
+}
+
+func (e InvalidChecksumError) Error() string {
+	return fmt.Sprintf("invalid checksum: %q", e.Checksum)
+}
+
+type MismatchError struct {
+	Name     string
+	Expected string
+	Actual   string
+}
+
+func NewMismatchError(name, expected, actual string) *MismatchError {
+	return &MismatchError{
+		Name:     name,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
+func (e MismatchError) Error() string {
+	return fmt.Sprintf(
+		"checksum mismatch for %q: expected %s, got %s",
+		e.Name, e.Expected, e.Actual,
+	)
+}
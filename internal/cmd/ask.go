@@ -18,6 +18,18 @@ func Ask(q string) string {
 	return response
 }
 
+// AskWithDefault asks the given question, the given default value is returned
+// if the answer is empty.
+func AskWithDefault(q, def string) string {
+	if def != "" {
+		q = fmt.Sprintf("%s (%s)", q, def)
+	}
+	if response := strings.TrimSpace(Ask(q)); response != "" {
+		return response
+	}
+	return def
+}
+
 func AskForConfirmation(q string) bool {
 	reader := bufio.NewReader(os.Stdin)
 
@@ -37,3 +49,17 @@ func AskForConfirmation(q string) bool {
 		}
 	}
 }
+
+// IsTerminal returns whether the standard input is a terminal, i.e. whether
+// questions can be asked.
+func IsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// The null device is also a character device.
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
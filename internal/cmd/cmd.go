@@ -26,9 +26,17 @@ type Command struct {
 
 	// A list of sub commands.
 	Commands []Command
+	// The method that gets called with the options of a command with sub
+	// commands, before the sub command gets called. These options have to be
+	// placed before the name of the sub command.
+	// e.g. oko -C dir build
+	Before func(options map[string]string) error
 
 	// A list of arguments.
 	Args []string
+	// Whether additional arguments are allowed after the listed arguments.
+	// Everything after `--` is considered an argument.
+	Variadic bool
 	// Options of the command.
 	// e.g. --all, etc.
 	Options []Option
@@ -40,6 +48,15 @@ func (c Command) Call(args ...string) error {
 	if c.Method != nil {
 		return c.method(args)
 	}
+	if len(c.Options) != 0 {
+		var options map[string]string
+		args, options = c.extractLeadingOptions(args)
+		if c.Before != nil {
+			if err := c.Before(options); err != nil {
+				return err
+			}
+		}
+	}
 	if len(args) == 0 || args[0] == "help" {
 		c.Help()
 		return nil
@@ -51,12 +68,18 @@ func (c Command) Call(args ...string) error {
 // expected amount.
 func (c Command) checkArguments(args []string) error {
 	l := len(c.Args)
+	if c.Variadic && l <= len(args) {
+		return nil
+	}
 	if len(args) != l {
 		var s []string
 		for _, a := range c.Args {
 			s = append(s, fmt.Sprintf("<%s>", a))
 		}
 
+		if c.Variadic {
+			return NewInvalidArgumentsError(fmt.Sprintf("expected at least %d argument(s): %s", l, strings.Join(s, " ")))
+		}
 		switch l {
 		case 0:
 			return NewInvalidArgumentsError("expected no argument")
@@ -91,44 +114,85 @@ func (c Command) extractOptions(args []string) ([]string, map[string]string) {
 		arg       string
 		options   = make(map[string]string)
 	)
-	for _, a := range args {
+	for i, a := range args {
 		if arg != "" {
 			options[arg] = a
 			arg = ""
 			continue
 		}
+		if a == "--" {
+			// Everything after `--` is an argument.
+			arguments = append(arguments, args[i+1:]...)
+			break
+		}
 
-		if a, ok := trimPrefix(a, "--"); ok {
-			var cont bool
-			for _, o := range c.Options {
-				if a, ok := trimPrefix(a, o.Name); ok {
-					if a, ok := trimPrefix(a, "="); ok && o.HasValue {
-						if a != "" {
-							options[o.Name] = a
-							cont = true
-							break
-						}
-					}
-					if a == "" {
-						if o.HasValue {
-							arg = o.Name
-						} else {
-							options[o.Name] = ""
-						}
-						cont = true
-						break
-					}
-				}
-			}
-			if cont {
-				continue
+		if o, value, ok := findOption(c.Options, a); ok {
+			switch {
+			case !o.HasValue:
+				options[o.Name] = ""
+			case value != "":
+				options[o.Name] = value
+			default:
+				// The value is the next argument.
+				arg = o.Name
 			}
+			continue
 		}
 		arguments = append(arguments, a)
 	}
 	return arguments, options
 }
 
+// extractLeadingOptions extracts the options in front of the first argument.
+// Returns the remaining arguments.
+func (c Command) extractLeadingOptions(args []string) ([]string, map[string]string) {
+	options := make(map[string]string)
+	for len(args) != 0 {
+		o, value, ok := findOption(c.Options, args[0])
+		if !ok {
+			break
+		}
+		args = args[1:]
+		switch {
+		case !o.HasValue:
+			options[o.Name] = ""
+		case value != "":
+			options[o.Name] = value
+		case len(args) != 0:
+			// The value is the next argument.
+			options[o.Name] = args[0]
+			args = args[1:]
+		}
+	}
+	return args, options
+}
+
+// findOption returns the option that matches the given argument, i.e.
+// `--name`, `--name=value`, `-s` or `-svalue`. Also returns the value if it is
+// part of the argument.
+func findOption(options []Option, a string) (Option, string, bool) {
+	if name, ok := trimPrefix(a, "--"); ok {
+		name, value, hasValue := strings.Cut(name, "=")
+		for _, o := range options {
+			if o.Name == name && (o.HasValue || !hasValue) {
+				return o, value, true
+			}
+		}
+		return Option{}, "", false
+	}
+	if short, ok := trimPrefix(a, "-"); ok {
+		for _, o := range options {
+			if o.Shorthand == "" {
+				continue
+			}
+			if value, ok := trimPrefix(short, o.Shorthand); ok && (o.HasValue || value == "") {
+				return o, value, true
+			}
+		}
+	}
+	return Option{}, "", false
+}
+
 func (c Command) method(args []string) error {
 	if len(args) == 1 && args[0] == "help" {
 		c.Help()
@@ -146,7 +210,10 @@ func (c Command) method(args []string) error {
 }
 
 type Option struct {
-	Name     string
-	Summary  string
-	HasValue bool
+	Name string
+	// A single letter alternative to the name.
+	// e.g. -C instead of --directory
+	Shorthand string
+	Summary   string
+	HasValue  bool
 }
@@ -11,8 +11,8 @@ var (
 		Name: "sub",
 		Args: []string{"c"},
 		Options: []cmd.Option{
-			{"all", "", false},
-			{"v", "", true},
+			{Name: "all"},
+			{Name: "v", HasValue: true},
 		},
 		Method: func(args []string, options map[string]string) error {
 			fmt.Println(args, options)
@@ -26,6 +26,28 @@ var (
 	}
 )
 
+func ExampleCommand_Call_variadic() {
+	v := cmd.Command{
+		Name:     "variadic",
+		Args:     []string{"c"},
+		Variadic: true,
+		Options: []cmd.Option{
+			{Name: "all"},
+		},
+		Method: func(args []string, options map[string]string) error {
+			fmt.Println(args, options)
+			return nil
+		},
+	}
+	_ = v.Call("c")
+	_ = v.Call("c", "d", "--all")
+	_ = v.Call("c", "--", "--all", "d")
+	// Output:
+	// [c] map[]
+	// [c d] map[all:]
+	// [c --all d] map[]
+}
+
 func ExampleCommand_Help() {
 	c.Help()
 	// Output:
@@ -60,3 +82,28 @@ func ExampleCommand_Help_subC() {
 	// [c] map[v:0]
 	// [c] map[v:0]
 }
+
+func ExampleCommand_Call_options() {
+	g := cmd.Command{
+		Name:     "global",
+		Commands: []cmd.Command{s},
+		Options: []cmd.Option{
+			{Name: "directory", Shorthand: "C", HasValue: true},
+			{Name: "verbose"},
+		},
+		Before: func(options map[string]string) error {
+			fmt.Println(options)
+			return nil
+		},
+	}
+	_ = g.Call("-C", "dir", "sub", "c")
+	_ = g.Call("-Cdir", "--verbose", "sub", "c")
+	_ = g.Call("--directory=dir", "sub", "c", "--all")
+	// Output:
+	// map[directory:dir]
+	// [c] map[]
+	// map[directory:dir verbose:]
+	// [c] map[]
+	// map[directory:dir]
+	// [c] map[all:]
+}
@@ -18,26 +18,18 @@ func (c Command) Help() {
 		for _, a := range c.Args {
 			args = append(args, fmt.Sprintf("<%s>", a))
 		}
+		if c.Variadic {
+			args = append(args, "[args...]")
+		}
 		if len(args) != 0 {
 			fmt.Printf(" %s", strings.Join(args, " "))
 		}
 		fmt.Println()
 
-		var options [][]string
-		for _, o := range c.Options {
-			var option = []string{o.Name}
-			if o.HasValue {
-				option = append(option, "<value>")
-			}
-			if len(o.Summary) != 0 {
-				option = append(option, o.Summary)
-			}
-			options = append(options, option)
-		}
-		if len(options) != 0 {
+		if len(c.Options) != 0 {
 			fmt.Println()
 			fmt.Println("Optional arguments:")
-			fmt.Println(FormatTable(options, "\t", "\n", "\t"))
+			fmt.Println(formatOptions(c.Options))
 		}
 		fmt.Println()
 	} else {
@@ -52,9 +44,36 @@ func (c Command) Help() {
 			)
 		}
 
+		if len(c.Options) != 0 {
+			fmt.Print(" [options]")
+		}
 		fmt.Println(" <command>")
 		fmt.Println()
 		fmt.Println("Commands:")
 		fmt.Println(FormatTable(cmds, "\t", "\n", "\t"))
+		if len(c.Options) != 0 {
+			fmt.Println()
+			fmt.Println("Options:")
+			fmt.Println(formatOptions(c.Options))
+		}
+	}
+}
+
+// formatOptions formats the given options as a table.
+func formatOptions(options []Option) string {
+	var table [][]string
+	for _, o := range options {
+		var option = []string{o.Name}
+		if o.Shorthand != "" {
+			option[0] = fmt.Sprintf("%s, -%s", o.Name, o.Shorthand)
+		}
+		if o.HasValue {
+			option = append(option, "<value>")
+		}
+		if len(o.Summary) != 0 {
+			option = append(option, o.Summary)
+		}
+		table = append(table, option)
 	}
+	return FormatTable(table, "\t", "\n", "\t")
 }
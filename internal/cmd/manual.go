@@ -5,9 +5,17 @@ import (
 	"strings"
 )
 
-// Returns a MD styled docs for the given list of commands.
-func Manual(commands []Command) string {
-	return strings.TrimSpace(manual("Commands", 1, []string{"oko"}, commands))
+// Returns a MD styled docs for the given list of commands, and the given
+// global options (if any).
+func Manual(commands []Command, options ...Option) string {
+	var man string
+	if len(options) != 0 {
+		man += fmt.Sprintf("%s Global Options\n\n", headerPrefix(1))
+		man += "Global options have to be placed before the command.\n\n"
+		man += manualOptions(options)
+	}
+	man += manual("Commands", 1, []string{"oko"}, commands)
+	return strings.TrimSpace(man)
 }
 
 func headerPrefix(indent int) string {
@@ -44,6 +52,9 @@ func manual(prefix string, indent int, parents []string, commands []Command) str
 			for _, arg := range cmd.Args {
 				args += fmt.Sprintf(" <%s>", arg)
 			}
+			if cmd.Variadic {
+				args += " [args...]"
+			}
 			man += fmt.Sprintf("```shell\n%s %s%s\n```\n\n", strings.Join(parents, " "), cmd.Name, args)
 
 			// Args.
@@ -58,21 +69,30 @@ func manual(prefix string, indent int, parents []string, commands []Command) str
 			// Options
 			if len(cmd.Options) != 0 {
 				man += fmt.Sprintf("%s Options\n\n", headerPrefix(indent+2))
-				man += "|name|value|\n|---|---|\n"
-				for _, o := range cmd.Options {
-					man += fmt.Sprintf("|**%s**|", o.Name)
-					if o.HasValue {
-						if len(o.Summary) != 0 {
-							man += fmt.Sprintf("*%s*", o.Summary)
-						} else {
-							man += "*value*"
-						}
-					}
-					man += "|\n"
-				}
-				man += "\n"
+				man += manualOptions(cmd.Options)
 			}
 		}
 	}
 	return man
 }
+
+// manualOptions returns a MD table of the given options.
+func manualOptions(options []Option) string {
+	man := "|name|value|\n|---|---|\n"
+	for _, o := range options {
+		man += fmt.Sprintf("|**%s**", o.Name)
+		if o.Shorthand != "" {
+			man += fmt.Sprintf(" (**-%s**)", o.Shorthand)
+		}
+		man += "|"
+		if o.HasValue {
+			if len(o.Summary) != 0 {
+				man += fmt.Sprintf("*%s*", o.Summary)
+			} else {
+				man += "*value*"
+			}
+		}
+		man += "|\n"
+	}
+	return man + "\n"
+}
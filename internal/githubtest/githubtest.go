@@ -0,0 +1,217 @@
+// Package githubtest provides a fake GitHub server for end-to-end tests.
+package githubtest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/internet-computer/oko/github"
+	okotar "github.com/internet-computer/oko/internal/tar"
+)
+
+// Placeholder gets replaced by the URL of the server in all served files.
+const Placeholder = "{{server}}"
+
+// Server emulates the parts of GitHub that are used by Oko:
+//   - the releases API: `/api/repos/{org}/{repo}/releases`,
+//   - the rate limit API: `/api/rate_limit`,
+//   - repository archives: `/{org}/{repo}/archive/{version}/.tar.gz`,
+//   - release assets: `/{org}/{repo}/releases/download/{tag}/{name}`.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	archives map[string]map[string]string // {org}/{repo}@{version} -> files
+	releases map[string][]release         // {org}/{repo} -> releases, latest first
+	requests []string
+}
+
+type release struct {
+	tag    string
+	assets map[string][]byte
+}
+
+// NewServer starts a fake GitHub server, which is closed at the end of the
+// test.
+func NewServer(t *testing.T) *Server {
+	s := &Server{
+		archives: make(map[string]map[string]string),
+		releases: make(map[string][]release),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Install points the `github` and `tar` packages, and their HTTP clients, to
+// the server until the end of the test. Repositories installed from GitHub get
+// the URL of the server.
+func (s *Server) Install(t *testing.T) {
+	url, apiURL, apiClient, client := github.URL, github.APIURL, github.Client, okotar.Client
+	t.Cleanup(func() {
+		github.URL, github.APIURL, github.Client, okotar.Client = url, apiURL, apiClient, client
+	})
+	github.URL = s.URL
+	github.APIURL = s.URL + "/api"
+	github.Client = s.Client()
+	okotar.Client = s.Client()
+}
+
+// Repository returns the URL of the given repository, i.e. `{org}/{repo}`.
+func (s *Server) Repository(repo string) string {
+	return fmt.Sprintf("%s/%s", s.URL, repo)
+}
+
+// AddArchive adds an archive of the given files for the given version of the
+// repository, i.e. `{org}/{repo}`.
+func (s *Server) AddArchive(repo, version string, files map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.archives[fmt.Sprintf("%s@%s", repo, version)] = files
+}
+
+// AddRelease adds a release with the given assets to the repository. The last
+// added release is the latest release.
+func (s *Server) AddRelease(repo, tag string, assets map[string][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releases[repo] = append([]release{{tag: tag, assets: assets}}, s.releases[repo]...)
+}
+
+// Requests returns the paths of all requests the server received.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.URL.Path)
+
+	p := strings.TrimPrefix(r.URL.Path, "/")
+	switch {
+	case p == "api/rate_limit":
+		writeJSON(w, map[string]interface{}{
+			"rate": map[string]int{"limit": 60, "remaining": 60},
+		})
+	case strings.HasPrefix(p, "api/repos/") && strings.HasSuffix(p, "/releases"):
+		repo := strings.TrimSuffix(strings.TrimPrefix(p, "api/repos/"), "/releases")
+		releases := make([]map[string]string, 0)
+		for _, r := range s.releases[repo] {
+			releases = append(releases, map[string]string{"tag_name": r.tag})
+		}
+		writeJSON(w, releases)
+	case strings.Contains(p, "/archive/"):
+		repo, version, _ := strings.Cut(strings.TrimSuffix(p, "/.tar.gz"), "/archive/")
+		files, ok := s.archives[fmt.Sprintf("%s@%s", repo, version)]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		contents := make(map[string]string)
+		for name, content := range files {
+			contents[name] = strings.ReplaceAll(content, Placeholder, s.URL)
+		}
+		dir := fmt.Sprintf("%s-%s", path.Base(repo), strings.TrimPrefix(version, "v"))
+		raw, err := Archive(dir, contents)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(raw)
+	case strings.Contains(p, "/releases/download/"):
+		repo, asset, _ := strings.Cut(p, "/releases/download/")
+		tag, name, _ := strings.Cut(asset, "/")
+		for _, r := range s.releases[repo] {
+			if raw, ok := r.assets[name]; r.tag == tag && ok {
+				_, _ = w.Write(bytes.ReplaceAll(raw, []byte(Placeholder), []byte(s.URL)))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(raw)
+}
+
+// Archive returns a gzipped tar archive of the given files, in the given
+// directory (or the root of the archive if empty). Parent directories are added
+// before the files they contain. Files starting with `#!` are executable.
+func Archive(dir string, files map[string]string) ([]byte, error) {
+	var b bytes.Buffer
+	gzw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gzw)
+
+	dirs := make(map[string]bool)
+	var writeDir func(name string) error
+	writeDir = func(name string) error {
+		if name == "." || name == "" || dirs[name] {
+			return nil
+		}
+		dirs[name] = true
+		if err := writeDir(path.Dir(name)); err != nil {
+			return err
+		}
+		return tw.WriteHeader(&tar.Header{Name: name + "/", Typeflag: tar.TypeDir, Mode: 0o755})
+	}
+	if err := writeDir(dir); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content := files[name]
+		name := path.Join(dir, name)
+		if err := writeDir(path.Dir(name)); err != nil {
+			return nil, err
+		}
+		mode := int64(0o644)
+		if strings.HasPrefix(content, "#!") {
+			mode = 0o755
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Typeflag: tar.TypeReg,
+			Mode:     mode,
+			Size:     int64(len(content)),
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,88 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Marshal returns the XML encoding of the given test suites.
+func Marshal(suites ...TestSuite) ([]byte, error) {
+	raw, err := xml.MarshalIndent(TestSuites{
+		Suites: suites,
+	}, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), raw...), nil
+}
+
+// Save writes the given test suites to the given path.
+func Save(path string, suites ...TestSuite) error {
+	raw, err := Marshal(suites...)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// Failure describes why a test case failed.
+type Failure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// TestCase is a single test case.
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	SystemOut string   `xml:"system-out,omitempty"`
+
+	seconds float64
+}
+
+// NewTestCase creates a new test case that took the given duration.
+func NewTestCase(name, className string, duration time.Duration) TestCase {
+	return TestCase{
+		Name:      name,
+		ClassName: className,
+		Time:      fmt.Sprintf("%.3f", duration.Seconds()),
+		seconds:   duration.Seconds(),
+	}
+}
+
+// TestSuite is a collection of test cases.
+type TestSuite struct {
+	Name     string     `xml:"name,attr"`
+	Tests    int        `xml:"tests,attr"`
+	Failures int        `xml:"failures,attr"`
+	Time     string     `xml:"time,attr"`
+	Cases    []TestCase `xml:"testcase"`
+}
+
+// NewTestSuite creates a new test suite based on the given test cases.
+func NewTestSuite(name string, cases []TestCase) TestSuite {
+	suite := TestSuite{
+		Name:  name,
+		Tests: len(cases),
+		Cases: cases,
+	}
+	var total float64
+	for _, c := range cases {
+		if c.Failure != nil {
+			suite.Failures++
+		}
+		total += c.seconds
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+	return suite
+}
+
+// TestSuites is the root element of a JUnit XML report.
+type TestSuites struct {
+	XMLName xml.Name    `xml:"testsuites"`
+	Suites  []TestSuite `xml:"testsuite"`
+}
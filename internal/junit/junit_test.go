@@ -0,0 +1,29 @@
+package junit_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/internet-computer/oko/internal/junit"
+)
+
+func ExampleMarshal() {
+	pass := junit.NewTestCase("src/a.test.mo", "src", time.Second)
+	fail := junit.NewTestCase("src/b.test.mo", "src", 500*time.Millisecond)
+	fail.Failure = &junit.Failure{
+		Message:  "exit status 1",
+		Contents: "assertion failed",
+	}
+	raw, _ := junit.Marshal(junit.NewTestSuite("oko", []junit.TestCase{pass, fail}))
+	fmt.Println(string(raw))
+	// Output:
+	// <?xml version="1.0" encoding="UTF-8"?>
+	// <testsuites>
+	// 	<testsuite name="oko" tests="2" failures="1" time="1.500">
+	// 		<testcase name="src/a.test.mo" classname="src" time="1.000"></testcase>
+	// 		<testcase name="src/b.test.mo" classname="src" time="0.500">
+	// 			<failure message="exit status 1">assertion failed</failure>
+	// 		</testcase>
+	// 	</testsuite>
+	// </testsuites>
+}
@@ -0,0 +1,23 @@
+package lock
+
+import "fmt"
+
+type LockError struct {
+	Path string
+	Err  error
+}
+
+func NewLockError(path string, err error) *LockError {
+	return &LockError{
+		Path: path,
+		Err:  err,
+	}
+}
+
+func (e LockError) Error() string {
+	return fmt.Sprintf("could not lock %q: %s", e.Path, e.Err)
+}
+
+func (e LockError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,45 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Lock is an advisory lock on a file. It only prevents other processes that
+// use the same lock file from acquiring the lock.
+type Lock struct {
+	file *os.File
+}
+
+// New acquires an exclusive lock on the file at the given path. The file (and
+// its directory) gets created if it does not exist yet. Blocks until the lock
+// is acquired.
+func New(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, NewLockError(path, err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, NewLockError(path, err)
+	}
+	if err := lock(file); err != nil {
+		_ = file.Close()
+		return nil, NewLockError(path, err)
+	}
+	return &Lock{
+		file: file,
+	}, nil
+}
+
+// Unlock releases the lock. The lock file is not removed, since other
+// processes might be waiting on it.
+func (l *Lock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		_ = l.file.Close()
+		return NewLockError(l.file.Name(), err)
+	}
+	if err := l.file.Close(); err != nil {
+		return NewLockError(l.file.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package lock
+
+import "os"
+
+// lock always succeeds, file locking is not supported on this platform.
+func lock(_ *os.File) error {
+	return nil
+}
+
+func unlock(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package lock_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/internet-computer/oko/internal/lock"
+)
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir", ".lock")
+	l, err := lock.New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locked := make(chan *lock.Lock)
+	go func() {
+		l, err := lock.New(path)
+		if err != nil {
+			t.Error(err)
+		}
+		locked <- l
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock acquired twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case l := <-locked:
+		if err := l.Unlock(); err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lock not released")
+	}
+}
@@ -2,94 +2,76 @@ package tar
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
-	"errors"
-	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
-func Download(url string, path string) error {
-	raw, err := http.Get(url)
-	if err != nil {
-		return NewTarError(err)
-	}
-	if raw.StatusCode != 200 {
-		return NewUnexpectedStatusCodeError(raw.StatusCode)
-	}
-	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+// ExtractGz extracts the given (gzipped) archive into the given path.
+func ExtractGz(raw []byte, path string) error {
+	return extract(bytes.NewReader(raw), path)
+}
+
+func extract(r io.Reader, path string) error {
+	if err := os.MkdirAll(path, 0o755); err != nil {
 		return NewTarError(err)
 	}
-	gzr, err := gzip.NewReader(raw.Body)
+	gzr, err := gzip.NewReader(r)
 	if err != nil {
 		return NewTarError(err)
 	}
 	tr := tar.NewReader(gzr)
-	for h, err := tr.Next(); err == nil; h, err = tr.Next() {
+	for h, err := tr.Next(); err != io.EOF; h, err = tr.Next() {
+		if err != nil {
+			return NewTarError(err)
+		}
+		name, err := target(path, h.Name)
+		if err != nil {
+			return err
+		}
 		switch h.Typeflag {
 		case tar.TypeDir:
-			if err := os.Mkdir(fmt.Sprintf("%s/%s", path, h.Name), os.ModePerm); err != nil {
+			if err := os.Mkdir(name, 0o755); err != nil {
 				if os.IsExist(err) {
-					return nil
+					continue
 				}
 				return NewTarError(err)
 			}
 		case tar.TypeReg:
-			file, err := os.Create(fmt.Sprintf("%s/%s", path, h.Name))
-			if err != nil {
-				return NewTarError(err)
-			}
-			defer file.Close()
-			if _, err := io.Copy(file, tr); err != nil {
+			if err := writeFile(name, tr, h.FileInfo().Mode()); err != nil {
 				return NewTarError(err)
 			}
 		}
 	}
-	if errors.Is(err, io.EOF) {
-		return NewTarError(err)
-	}
 	return nil
 }
 
-func DownloadGz(url string, path string) error {
-	raw, err := http.Get(url)
-	if err != nil {
-		return NewTarError(err)
+// target returns the path the entry with the given name gets extracted to.
+func target(path, name string) (string, error) {
+	p := filepath.Join(path, name)
+	if p != filepath.Clean(path) && !strings.HasPrefix(p, filepath.Clean(path)+string(os.PathSeparator)) {
+		return "", NewIllegalPathError(name)
 	}
-	if raw.StatusCode != 200 {
-		return NewUnexpectedStatusCodeError(raw.StatusCode)
-	}
-	if err := os.MkdirAll(path, os.ModePerm); err != nil {
-		return NewTarError(err)
+	return p, nil
+}
+
+// writeFile writes the given file, only keeping the executable bits of the
+// given mode.
+func writeFile(name string, r io.Reader, mode os.FileMode) error {
+	perm := os.FileMode(0o644)
+	if mode&0o111 != 0 {
+		perm = 0o755
 	}
-	gzr, err := gzip.NewReader(raw.Body)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
 	if err != nil {
-		return NewTarError(err)
-	}
-	tr := tar.NewReader(gzr)
-	for h, err := tr.Next(); err == nil; h, err = tr.Next() {
-		switch h.Typeflag {
-		case tar.TypeDir:
-			if err := os.Mkdir(fmt.Sprintf("%s/%s", path, h.Name), os.ModePerm); err != nil {
-				if os.IsExist(err) {
-					continue
-				}
-				return NewTarError(err)
-			}
-		case tar.TypeReg:
-			file, err := os.Create(fmt.Sprintf("%s/%s", path, h.Name))
-			if err != nil {
-				return NewTarError(err)
-			}
-			defer file.Close()
-			if _, err := io.Copy(file, tr); err != nil {
-				return NewTarError(err)
-			}
-		}
+		return err
 	}
-	if errors.Is(err, io.EOF) {
-		return NewTarError(err)
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
 	}
-	return nil
+	return file.Close()
 }
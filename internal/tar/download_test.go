@@ -0,0 +1,68 @@
+package tar_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	oko "github.com/internet-computer/oko/internal/tar"
+)
+
+func TestExtractGz(t *testing.T) {
+	dir := t.TempDir()
+	if err := oko.ExtractGz(archive(t, map[string]int64{
+		"moc":       0o777,
+		"README.md": 0o666,
+	}), dir); err != nil {
+		t.Fatal(err)
+	}
+	for name, perm := range map[string]os.FileMode{
+		"moc":       0o755,
+		"README.md": 0o644,
+	} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != perm {
+			t.Errorf("%s: expected %s, got %s", name, perm, info.Mode().Perm())
+		}
+	}
+}
+
+func TestExtractGz_illegalPath(t *testing.T) {
+	if err := oko.ExtractGz(archive(t, map[string]int64{
+		"../moc": 0o755,
+	}), t.TempDir()); err == nil {
+		t.Fatal()
+	}
+}
+
+func archive(t *testing.T, files map[string]int64) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, mode := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     mode,
+			Size:     int64(len(name)),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
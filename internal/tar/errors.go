@@ -2,6 +2,20 @@ package tar
 
 import "fmt"
 
+type IllegalPathError struct {
+	Name string
+}
+
+func NewIllegalPathError(name string) *IllegalPathError {
+	return &IllegalPathError{
+		Name: name,
+	}
+}
+
+func (e IllegalPathError) Error() string {
+	return fmt.Sprintf("illegal path in archive: %q", e.Name)
+}
+
 type TarError struct {
 	Err error
 }
@@ -0,0 +1,27 @@
+package tar
+
+import (
+	"io"
+	"net/http"
+)
+
+// Client is the HTTP client used to fetch files.
+var Client = http.DefaultClient
+
+// Fetch returns the body of the given url. Returns an error if the status code
+// is not 200.
+func Fetch(url string) ([]byte, error) {
+	resp, err := Client.Get(url)
+	if err != nil {
+		return nil, NewTarError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewUnexpectedStatusCodeError(resp.StatusCode)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewTarError(err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,41 @@
+package mops
+
+import "fmt"
+
+type MopsError struct {
+	Err error
+}
+
+func NewMopsError(err error) *MopsError {
+	return &MopsError{
+		Err: err,
+	}
+}
+
+func (e MopsError) Error() string {
+	return fmt.Sprintf("mops error: %s", e.Err)
+}
+
+func (e MopsError) Unwrap() error {
+	return e.Err
+}
+
+type SyntaxError struct {
+	Line int
+	Err  error
+}
+
+func NewSyntaxError(line int, err error) *SyntaxError {
+	return &SyntaxError{
+		Line: line,
+		Err:  err,
+	}
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error: %s", e.Err)
+}
+
+func (e SyntaxError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,122 @@
+package mops
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/internet-computer/oko/config"
+)
+
+// Dependency is a dependency in the `[dependencies]` table of a `mops.toml`
+// file. Exactly one of `Version` (a version in the mops registry), `Repository`
+// (a GitHub repository) or `Path` (a local directory) is the source of the
+// package.
+type Dependency struct {
+	Name string
+	// Version is either the version in the mops registry, or the reference
+	// (i.e. tag) in the GitHub repository.
+	Version    string
+	Repository string
+	Path       string
+}
+
+// IsGitHub returns whether the dependency is hosted on GitHub.
+func (d Dependency) IsGitHub() bool {
+	return d.Repository != ""
+}
+
+// IsLocal returns whether the dependency is a local directory.
+func (d Dependency) IsLocal() bool {
+	return d.Path != ""
+}
+
+// Oko converts a GitHub hosted dependency to an Oko package.
+func (d Dependency) Oko() config.PackageInfoRemote {
+	return config.PackageInfoRemote{
+		Name:       d.Name,
+		Repository: d.Repository,
+		Version:    d.Version,
+	}
+}
+
+type Manifest struct {
+	Name    string
+	Version string
+	// Compiler is the version of `moc` in the `[toolchain]` table, if any.
+	Compiler     *string
+	Dependencies []Dependency
+}
+
+func LoadManifest(path string) (*Manifest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, NewMopsError(err)
+	}
+	return NewManifest(raw)
+}
+
+// NewManifest parses a `mops.toml` file. Development dependencies and all other
+// tables are ignored. The dependencies are sorted by name.
+func NewManifest(raw []byte) (*Manifest, error) {
+	var file manifestFile
+	if _, err := toml.Decode(string(raw), &file); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, NewMopsError(NewSyntaxError(parseErr.Position.Line, err))
+		}
+		return nil, NewMopsError(err)
+	}
+	manifest := Manifest{
+		Name:     file.Package.Name,
+		Version:  file.Package.Version,
+		Compiler: file.Toolchain.Moc,
+	}
+	for name, value := range file.Dependencies {
+		manifest.Dependencies = append(manifest.Dependencies, newDependency(name, value))
+	}
+	sort.Slice(manifest.Dependencies, func(i, j int) bool {
+		return manifest.Dependencies[i].Name < manifest.Dependencies[j].Name
+	})
+	return &manifest, nil
+}
+
+// manifestFile is the part of a `mops.toml` file that is used by Oko.
+type manifestFile struct {
+	Package struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+	} `toml:"package"`
+	Toolchain struct {
+		Moc *string `toml:"moc"`
+	} `toml:"toolchain"`
+	Dependencies map[string]string `toml:"dependencies"`
+}
+
+// newDependency parses the value of a dependency, i.e. `0.7.4`,
+// `https://github.com/{org}/{repo}#{ref}` or a local path.
+func newDependency(name, value string) Dependency {
+	switch {
+	case strings.HasPrefix(value, "https://github.com/"):
+		repo, ref, _ := strings.Cut(value, "#")
+		// Ignore the commit hash, i.e. `#{ref}@{commit}`.
+		ref, _, _ = strings.Cut(ref, "@")
+		return Dependency{
+			Name:       name,
+			Repository: strings.TrimSuffix(repo, ".git"),
+			Version:    ref,
+		}
+	case strings.HasPrefix(value, ".") || strings.HasPrefix(value, "/"):
+		return Dependency{
+			Name: name,
+			Path: value,
+		}
+	default:
+		return Dependency{
+			Name:    name,
+			Version: value,
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package mops_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/internet-computer/oko/mops"
+)
+
+func ExampleLoadManifest() {
+	manifest, _ := mops.LoadManifest("testdata/mops.toml")
+	fmt.Println(manifest.Name, manifest.Version, *manifest.Compiler)
+	for _, dep := range manifest.Dependencies {
+		fmt.Printf("%s %q %q %q\n", dep.Name, dep.Repository, dep.Version, dep.Path)
+	}
+	// Output:
+	// example 0.1.0 0.7.4
+	// base "" "0.7.4" ""
+	// local-lib "" "" "../lib"
+	// testing "https://github.com/internet-computer/testing.mo" "v0.1.0" ""
+}
+
+func TestNewManifest(t *testing.T) {
+	for _, test := range []struct {
+		raw  string
+		line int
+	}{
+		{"[dependencies]]\nbase = \"0.7.4\"", 1},
+		{"[dependencies]\nbase = 0.7.4", 2},
+		{"[dependencies]\nbase = \"\\q\"", 2},
+		{"[dependencies]\nbase = \"0.7.4\"\nbase = \"0.7.5\"", 3},
+		{"[package]\n[package]", 2},
+	} {
+		_, err := mops.NewManifest([]byte(test.raw))
+		var syntaxErr *mops.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected syntax error, got %v", test.raw, err)
+			continue
+		}
+		if syntaxErr.Line != test.line {
+			t.Errorf("%q: expected line %d, got %d", test.raw, test.line, syntaxErr.Line)
+		}
+	}
+
+	// Dependencies have to be strings.
+	if _, err := mops.NewManifest([]byte("[dependencies]\nbase = [\"0.7.4\"]")); err == nil {
+		t.Error("expected error")
+	}
+
+	// Unsupported values outside of the dependencies are ignored.
+	manifest, err := mops.NewManifest([]byte(`[package]
+name = "lib"
+keywords = ["motoko", "lib"]
+files = { include = ["src/**/*.mo"] }
+
+[dependencies]
+base = "0.7.4"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != "lib" || len(manifest.Dependencies) != 1 {
+		t.Error(manifest)
+	}
+
+	manifest, err = mops.NewManifest([]byte("[dependencies]\n'a#b' = \"https://github.com/org/repo.git#main\" # comment"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep := manifest.Dependencies[0]; dep.Name != "a#b" || dep.Repository != "https://github.com/org/repo" || dep.Version != "main" {
+		t.Error(dep)
+	}
+}
@@ -0,0 +1,21 @@
+package store
+
+import "fmt"
+
+type StoreError struct {
+	Err error
+}
+
+func NewStoreError(err error) *StoreError {
+	return &StoreError{
+		Err: err,
+	}
+}
+
+func (e StoreError) Error() string {
+	return fmt.Sprintf("store error: %s", e.Err)
+}
+
+func (e StoreError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/internet-computer/oko/internal/lock"
+	"github.com/internet-computer/oko/internal/tar"
+)
+
+// EnvHome is the environment variable that sets the root directory of the
+// store, e.g. to share the store between packages.
+const EnvHome = "OKO_HOME"
+
+// Store is the directory that contains the downloaded packages and toolchains.
+// The layout of the store is:
+//   - `packages/{host}/{org}/{repo}@{version}`: the contents of a package,
+//   - `bin/{version}`: the Motoko compiler,
+//   - `bin/didc/{version}/didc`: didc.
+//
+// Hidden files in the root directory are used by the store itself, e.g.
+// `.manifests` lists the package files that use the store.
+type Store struct {
+	Root string
+}
+
+// New returns the store in the given root directory.
+func New(root string) Store {
+	return Store{
+		Root: root,
+	}
+}
+
+// PackageDir returns the directory of the given version of the repository.
+// Repositories with the same name, but from different organizations or hosts,
+// do not share a directory.
+func (s Store) PackageDir(repository, version string) string {
+	name := strings.TrimSuffix(repository, ".git")
+	if u, err := url.Parse(name); err == nil && u.Host != "" {
+		name = path.Join(u.Host, u.Path)
+	}
+	name = fmt.Sprintf("%s@%s", name, strings.ReplaceAll(version, "/", "_"))
+	return filepath.Join(s.Root, "packages", filepath.FromSlash(strings.ReplaceAll(name, ":", "_")))
+}
+
+// CompilerDir returns the directory of the given version of the Motoko compiler.
+func (s Store) CompilerDir(version string) string {
+	return filepath.Join(s.Root, "bin", version)
+}
+
+// DidcPath returns the path to the given version of didc.
+func (s Store) DidcPath(version string) string {
+	return filepath.Join(s.Root, "bin", "didc", version, "didc")
+}
+
+// Lock acquires the lock on the store. The lock should be held while
+// downloading into the store.
+func (s Store) Lock() (*lock.Lock, error) {
+	l, err := lock.New(filepath.Join(s.Root, ".lock"))
+	if err != nil {
+		return nil, NewStoreError(err)
+	}
+	return l, nil
+}
+
+// Register records that the package file at the given path uses the store, so
+// that cleaning the store for one package file keeps the packages of the
+// others. The lock should be held while registering.
+func (s Store) Register(manifest string) error {
+	abs, err := filepath.Abs(manifest)
+	if err != nil {
+		return NewStoreError(err)
+	}
+	manifests, err := s.registered()
+	if err != nil {
+		return err
+	}
+	for _, m := range manifests {
+		if m == abs {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(s.Root, 0o755); err != nil {
+		return NewStoreError(err)
+	}
+	f, err := os.OpenFile(filepath.Join(s.Root, ".manifests"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return NewStoreError(err)
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintln(f, abs); err != nil {
+		return NewStoreError(err)
+	}
+	return nil
+}
+
+// Manifests returns the absolute paths of the package files that are
+// registered to use the store. Package files that no longer exist are left out.
+func (s Store) Manifests() ([]string, error) {
+	manifests, err := s.registered()
+	if err != nil {
+		return nil, err
+	}
+	var existing []string
+	for _, m := range manifests {
+		if _, err := os.Stat(m); err == nil {
+			existing = append(existing, m)
+		}
+	}
+	return existing, nil
+}
+
+// registered returns all package files in `.manifests`.
+func (s Store) registered() ([]string, error) {
+	raw, err := os.ReadFile(filepath.Join(s.Root, ".manifests"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, NewStoreError(err)
+	}
+	var manifests []string
+	for _, line := range strings.Split(string(raw), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			manifests = append(manifests, line)
+		}
+	}
+	return manifests, nil
+}
+
+// DownloadPackage downloads the archive of the given version of the GitHub
+// hosted repository, and extracts it into the package directory. Does nothing
+// if the package directory already exists. Returns the package directory.
+func (s Store) DownloadPackage(repository, version string) (string, error) {
+	dir := s.PackageDir(repository, version)
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+	raw, err := tar.Fetch(fmt.Sprintf(
+		"%s/archive/%s/.tar.gz",
+		strings.TrimSuffix(repository, ".git"), version,
+	))
+	if err != nil {
+		return "", err
+	}
+
+	// Extract into a temporary directory first, so no partial packages end up
+	// in the store.
+	if err := os.MkdirAll(s.Root, 0o755); err != nil {
+		return "", NewStoreError(err)
+	}
+	tmp, err := os.MkdirTemp(s.Root, ".download-")
+	if err != nil {
+		return "", NewStoreError(err)
+	}
+	defer os.RemoveAll(tmp)
+	if err := tar.ExtractGz(raw, tmp); err != nil {
+		return "", err
+	}
+
+	// Archives of GitHub contain a single directory, `{repo}-{version}`.
+	src := tmp
+	if entries, err := os.ReadDir(tmp); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		src = filepath.Join(tmp, entries[0].Name())
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", NewStoreError(err)
+	}
+	if err := os.Rename(src, dir); err != nil {
+		return "", NewStoreError(err)
+	}
+	return dir, nil
+}
+
+// Stale returns all paths in the store that are neither one of the paths to
+// keep nor contain any of them.
+func (s Store) Stale(keep []string) ([]string, error) {
+	var clean []string
+	for _, k := range keep {
+		clean = append(clean, filepath.Clean(k))
+	}
+	stale, err := stalePaths(filepath.Clean(s.Root), clean)
+	if err != nil {
+		return nil, NewStoreError(err)
+	}
+	return stale, nil
+}
+
+// stalePaths returns all paths within the given directory that are neither one
+// of the paths to keep nor contain any of them. Hidden files are ignored.
+func stalePaths(dir string, keep []string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var stale []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		var kept, parent bool
+		for _, k := range keep {
+			if k == path {
+				kept = true
+				break
+			}
+			if strings.HasPrefix(k, path+string(os.PathSeparator)) {
+				parent = true
+			}
+		}
+		switch {
+		case kept:
+		case parent && entry.IsDir():
+			paths, err := stalePaths(path, keep)
+			if err != nil {
+				return nil, err
+			}
+			stale = append(stale, paths...)
+		default:
+			stale = append(stale, path)
+		}
+	}
+	return stale, nil
+}
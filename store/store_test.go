@@ -0,0 +1,107 @@
+package store_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/internet-computer/oko/internal/githubtest"
+	"github.com/internet-computer/oko/store"
+)
+
+func TestStore_PackageDir(t *testing.T) {
+	s := store.New("root")
+	for _, test := range []struct {
+		repository, version, dir string
+	}{
+		{"https://github.com/dfinity/motoko-base", "moc-0.7.4", "root/packages/github.com/dfinity/motoko-base@moc-0.7.4"},
+		{"https://github.com/aviate-labs/motoko-base.git", "moc-0.7.4", "root/packages/github.com/aviate-labs/motoko-base@moc-0.7.4"},
+		{"http://127.0.0.1:8000/org/base", "v0.1.0", "root/packages/127.0.0.1_8000/org/base@v0.1.0"},
+	} {
+		if dir := s.PackageDir(test.repository, test.version); dir != filepath.FromSlash(test.dir) {
+			t.Errorf("%s@%s: %s", test.repository, test.version, dir)
+		}
+	}
+}
+
+func TestStore_DownloadPackage(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddArchive("org/base", "v0.1.0", map[string]string{
+		"src/Base.mo": "module {}",
+	})
+	s := store.New(t.TempDir())
+	dir, err := s.DownloadPackage(server.Repository("org/base"), "v0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != s.PackageDir(server.Repository("org/base"), "v0.1.0") {
+		t.Error(dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "Base.mo")); err != nil {
+		t.Error(err)
+	}
+
+	// Downloaded packages are not downloaded again.
+	if _, err := s.DownloadPackage(server.Repository("org/base"), "v0.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if requests := server.Requests(); len(requests) != 1 {
+		t.Error(requests)
+	}
+
+	// Failed downloads leave nothing behind.
+	if _, err := s.DownloadPackage(server.Repository("org/unknown"), "v0.1.0"); err == nil {
+		t.Error()
+	}
+	if _, err := os.Stat(s.PackageDir(server.Repository("org/unknown"), "v0.1.0")); !os.IsNotExist(err) {
+		t.Error(err)
+	}
+}
+
+func TestStore_Stale(t *testing.T) {
+	s := store.New(t.TempDir())
+	keep := s.PackageDir("https://github.com/org/base", "v0.2.0")
+	stale := s.PackageDir("https://github.com/org/base", "v0.1.0")
+	for _, dir := range []string{keep, stale, s.CompilerDir("0.7.4"), filepath.Join(s.Root, ".download-0")} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths, err := s.Stale([]string{keep})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{filepath.Join(s.Root, "bin"), stale}; !reflect.DeepEqual(paths, expected) {
+		t.Error(paths)
+	}
+}
+
+func TestStore_Register(t *testing.T) {
+	s := store.New(t.TempDir())
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	for _, manifest := range []string{a, b, a} {
+		if err := os.WriteFile(manifest, []byte("{}"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Register(manifest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manifests, err := s.Manifests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{a, b}; !reflect.DeepEqual(manifests, expected) {
+		t.Error(manifests)
+	}
+
+	// Removed package files are left out.
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	if manifests, _ := s.Manifests(); !reflect.DeepEqual(manifests, []string{b}) {
+		t.Error(manifests)
+	}
+}
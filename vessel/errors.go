@@ -52,3 +52,58 @@ func NewVesselError(err error) *VesselError {
 func (e VesselError) Error() string {
 	return fmt.Sprintf("vessel error: %s", e.Err)
 }
+
+func (e VesselError) Unwrap() error {
+	return e.Err
+}
+
+type ImportError struct {
+	Import string
+	Err    error
+}
+
+func NewImportError(location string, err error) *ImportError {
+	return &ImportError{
+		Import: location,
+		Err:    err,
+	}
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("could not resolve import %q: %s", e.Import, e.Err)
+}
+
+func (e ImportError) Unwrap() error {
+	return e.Err
+}
+
+type IntegrityError struct {
+	Import   string
+	Expected []byte
+	Actual   []byte
+}
+
+func NewIntegrityError(location string, expected, actual []byte) *IntegrityError {
+	return &IntegrityError{
+		Import:   location,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
+func (e IntegrityError) Error() string {
+	return fmt.Sprintf(
+		"integrity check of import %q failed: expected sha256:%x, got sha256:%x",
+		e.Import, e.Expected[2:], e.Actual[2:],
+	)
+}
+
+type OfflineError struct{}
+
+func NewOfflineError() *OfflineError {
+	return &OfflineError{}
+}
+
+func (e OfflineError) Error() string {
+	return "not cached or mirrored, can not fetch remote imports in offline mode"
+}
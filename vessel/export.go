@@ -0,0 +1,142 @@
+package vessel
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/internet-computer/oko/config"
+)
+
+// NewManifestFromState creates a manifest from the direct dependencies of the
+// given package state. Every (alternative) name of a package is added as a
+// separate dependency, unless another package depends on it by that name: it is
+// then part of the package set. Local dependencies are not included.
+func NewManifestFromState(state config.PackageState) Manifest {
+	required := make(map[string]bool)
+	for _, packages := range []map[string]*config.PackageInfoRemote{
+		state.Dependencies,
+		state.TransitiveDependencies,
+	} {
+		for _, dep := range packages {
+			for _, name := range dep.Dependencies {
+				required[name] = true
+			}
+		}
+	}
+	var dependencies []string
+	for _, dep := range state.Dependencies {
+		for _, name := range append([]string{dep.Name}, dep.AlternativeNames...) {
+			if !required[name] {
+				dependencies = append(dependencies, name)
+			}
+		}
+	}
+	sort.Strings(dependencies)
+	return Manifest{
+		Compiler:     state.CompilerVersion,
+		Dependencies: dependencies,
+	}
+}
+
+// NewPackageSetFromState creates a package set from both the direct and the
+// transitive dependencies of the given package state. Every (alternative)
+// name of a package results in a separate package, which are merged again by
+// `Manifest.Oko`.
+func NewPackageSetFromState(state config.PackageState) PackageSet {
+	set := PackageSet{
+		Packages: make(map[string]Package),
+	}
+	for _, packages := range []map[string]*config.PackageInfoRemote{
+		state.Dependencies,
+		state.TransitiveDependencies,
+	} {
+		for _, dep := range packages {
+			for _, name := range append([]string{dep.Name}, dep.AlternativeNames...) {
+				set.Packages[name] = Package{
+					Name:         name,
+					Repo:         dep.Repository,
+					Version:      dep.Version,
+					Dependencies: dep.Dependencies,
+				}
+			}
+		}
+	}
+	return set
+}
+
+// MarshalDhall converts the manifest to a `vessel.dhall` file.
+func (m Manifest) MarshalDhall() []byte {
+	compiler := "None Text"
+	if m.Compiler != nil {
+		compiler = fmt.Sprintf("Some %s", dhallText(*m.Compiler))
+	}
+	return []byte(fmt.Sprintf(
+		"{ dependencies = %s\n, compiler = %s\n}\n",
+		dhallTextList(m.Dependencies), compiler,
+	))
+}
+
+// SaveDhall writes the manifest to the given path in the Vessel format.
+func (m Manifest) SaveDhall(path string) error {
+	if err := os.WriteFile(path, m.MarshalDhall(), 0o644); err != nil {
+		return NewVesselError(err)
+	}
+	return nil
+}
+
+// MarshalDhall converts the package set to a `package-set.dhall` file. The
+// packages are sorted by name.
+func (set PackageSet) MarshalDhall() []byte {
+	var names []string
+	for name := range set.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return []byte("[] : List { name : Text, repo : Text, version : Text, dependencies : List Text }\n")
+	}
+	var b strings.Builder
+	for i, name := range names {
+		pkg := set.Packages[name]
+		prefix := ","
+		if i == 0 {
+			prefix = "["
+		}
+		fmt.Fprintf(&b, "%s { name = %s\n", prefix, dhallText(pkg.Name))
+		fmt.Fprintf(&b, "  , repo = %s\n", dhallText(pkg.Repo))
+		fmt.Fprintf(&b, "  , version = %s\n", dhallText(pkg.Version))
+		fmt.Fprintf(&b, "  , dependencies = %s\n", dhallTextList(pkg.Dependencies))
+		b.WriteString("  }\n")
+	}
+	b.WriteString("]\n")
+	return []byte(b.String())
+}
+
+// SaveDhall writes the package set to the given path in the Vessel format.
+func (set PackageSet) SaveDhall(path string) error {
+	if err := os.WriteFile(path, set.MarshalDhall(), 0o644); err != nil {
+		return NewVesselError(err)
+	}
+	return nil
+}
+
+// dhallText converts the given string to a Dhall text literal.
+func dhallText(s string) string {
+	return strings.ReplaceAll(strconv.Quote(s), "${", "\\${")
+}
+
+// dhallTextList converts the given strings to a Dhall list of text literals.
+func dhallTextList(list []string) string {
+	if len(list) == 0 {
+		return "[] : List Text"
+	}
+	var texts []string
+	for _, s := range list {
+		texts = append(texts, dhallText(s))
+	}
+	return fmt.Sprintf("[ %s ]", strings.Join(texts, ", "))
+}
@@ -0,0 +1,228 @@
+package vessel
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/philandstuff/dhall-golang/v6"
+	"github.com/philandstuff/dhall-golang/v6/binary"
+	"github.com/philandstuff/dhall-golang/v6/core"
+	"github.com/philandstuff/dhall-golang/v6/imports"
+	"github.com/philandstuff/dhall-golang/v6/parser"
+	"github.com/philandstuff/dhall-golang/v6/term"
+)
+
+// Resolver resolves the imports of Dhall files.
+type Resolver struct {
+	// CacheDir is the directory that contains the frozen imports, i.e.
+	// imports with a sha256 hash. Uses the same layout as the standard Dhall
+	// cache. Imports are not cached if empty.
+	CacheDir string
+	// MirrorDir is the directory that contains local copies of remote
+	// imports, i.e. `https://{host}/{path}` is read from `{MirrorDir}/{host}/{path}`.
+	MirrorDir string
+	// Offline disables fetching remote imports that are not cached or mirrored.
+	Offline bool
+}
+
+// NewResolver returns a resolver that uses the standard Dhall cache.
+func NewResolver() Resolver {
+	dir, _ := imports.DhallCacheDir()
+	return Resolver{
+		CacheDir: dir,
+	}
+}
+
+// LoadManifest loads the manifest at the given path.
+func (r Resolver) LoadManifest(path string) (*Manifest, error) {
+	var manifest Manifest
+	if err := r.unmarshalFile(path, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// LoadPackageSet loads the package set at the given path. Relative imports
+// are resolved relative to the directory of the package set.
+func (r Resolver) LoadPackageSet(path string) (*PackageSet, error) {
+	var list []dhallPackage
+	if err := r.unmarshalFile(path, &list); err != nil {
+		return nil, err
+	}
+	return newDhallPackageSet(list)
+}
+
+// NewPackageSet parses the given package set. Relative imports are resolved
+// relative to the working directory.
+func (r Resolver) NewPackageSet(raw []byte) (*PackageSet, error) {
+	var list []dhallPackage
+	if err := r.unmarshal("-", raw, &list); err != nil {
+		return nil, err
+	}
+	return newDhallPackageSet(list)
+}
+
+// fetch returns the contents of the given import.
+func (r Resolver) fetch(here term.Fetchable, origin string) (string, error) {
+	remote, ok := here.(term.RemoteFile)
+	if !ok {
+		return here.Fetch(origin)
+	}
+	if r.MirrorDir != "" {
+		u, err := url.Parse(remote.String())
+		if err != nil {
+			return "", err
+		}
+		raw, err := os.ReadFile(filepath.Join(r.MirrorDir, u.Host, filepath.FromSlash(u.Path)))
+		if err == nil {
+			return string(raw), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	if r.Offline {
+		return "", NewOfflineError()
+	}
+	return remote.Fetch(origin)
+}
+
+// load resolves all imports of the given term. Based on `imports.LoadWith`,
+// but reads remote imports from the mirror directory if available.
+func (r Resolver) load(cache imports.DhallCache, e term.Term, ancestors ...term.Fetchable) (term.Term, error) {
+	switch e := e.(type) {
+	case term.Import:
+		here := e.Fetchable
+		origin := term.NullOrigin
+		if len(ancestors) != 0 {
+			origin = ancestors[len(ancestors)-1].Origin()
+
+			var err error
+			here, err = here.ChainOnto(ancestors[len(ancestors)-1])
+			if err != nil {
+				return nil, NewImportError(e.Fetchable.String(), err)
+			}
+		}
+		if e.ImportMode == term.Location {
+			return here.AsLocation(), nil
+		}
+
+		for _, ancestor := range ancestors {
+			if ancestor == here {
+				return nil, NewImportError(here.String(), fmt.Errorf("import cycle"))
+			}
+		}
+		if e.Hash != nil {
+			if expr := cache.Fetch(e.Hash); expr != nil {
+				return expr, nil
+			}
+		}
+		content, err := r.fetch(here, origin)
+		if err != nil {
+			return nil, NewImportError(here.String(), err)
+		}
+		var expr term.Term
+		if e.ImportMode == term.RawText {
+			expr = term.PlainText(content)
+		} else {
+			dynamicExpr, err := parser.Parse(here.String(), []byte(content))
+			if err != nil {
+				return nil, NewImportError(here.String(), err)
+			}
+			if expr, err = r.load(cache, dynamicExpr, append(ancestors, here)...); err != nil {
+				return nil, err
+			}
+			if _, err := core.TypeOf(expr); err != nil {
+				return nil, NewImportError(here.String(), err)
+			}
+		}
+
+		value := core.Eval(expr)
+		expr = core.Quote(value)
+		if e.Hash != nil {
+			hash, err := binary.SemanticHash(value)
+			if err != nil {
+				return nil, NewImportError(here.String(), err)
+			}
+			if !bytes.Equal(e.Hash, hash) {
+				return nil, NewIntegrityError(here.String(), e.Hash, hash)
+			}
+			cache.Save(hash, core.QuoteAlphaNormal(value))
+		}
+		return expr, nil
+	case term.Op:
+		if e.OpCode == term.ImportAltOp {
+			if l, err := r.load(cache, e.L, ancestors...); err == nil {
+				return l, nil
+			}
+			return r.load(cache, e.R, ancestors...)
+		}
+		l, err := r.load(cache, e.L, ancestors...)
+		if err != nil {
+			return nil, err
+		}
+		rhs, err := r.load(cache, e.R, ancestors...)
+		if err != nil {
+			return nil, err
+		}
+		return term.Op{OpCode: e.OpCode, L: l, R: rhs}, nil
+	default:
+		return term.MaybeTransformSubexprs(e, func(t term.Term) (term.Term, error) {
+			return r.load(cache, t, ancestors...)
+		})
+	}
+}
+
+// unmarshal parses the given Dhall expression, resolves its imports and
+// decodes it into the given value.
+func (r Resolver) unmarshal(name string, raw []byte, out interface{}, ancestors ...term.Fetchable) error {
+	expr, err := parser.Parse(name, raw)
+	if err != nil {
+		return NewVesselError(err)
+	}
+	var cache imports.DhallCache = imports.NoCache{}
+	if r.CacheDir != "" {
+		cache = localCache{
+			LocalCache: imports.NewLocalCache(r.CacheDir),
+			dir:        r.CacheDir,
+		}
+	}
+	resolved, err := r.load(cache, expr, ancestors...)
+	if err != nil {
+		return NewVesselError(err)
+	}
+	if _, err := core.TypeOf(resolved); err != nil {
+		return NewVesselError(err)
+	}
+	if err := dhall.Decode(core.Eval(resolved), out); err != nil {
+		return NewVesselError(err)
+	}
+	return nil
+}
+
+// unmarshalFile reads the Dhall file at the given path, resolves its imports
+// relative to the file and decodes it into the given value.
+func (r Resolver) unmarshalFile(path string, out interface{}) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return NewVesselError(err)
+	}
+	return r.unmarshal(path, raw, out, term.LocalFile(path))
+}
+
+// localCache is a local Dhall cache that creates its directory when the first
+// import gets saved.
+type localCache struct {
+	imports.LocalCache
+	dir string
+}
+
+func (c localCache) Save(hash []byte, expr term.Term) {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+	c.LocalCache.Save(hash, expr)
+}
@@ -0,0 +1,33 @@
+package vessel
+
+import (
+	"os"
+	"strings"
+
+	"github.com/internet-computer/oko/internal/tar"
+)
+
+// DefaultIndex is the package set that is used if no other package set is configured.
+const DefaultIndex = "https://github.com/dfinity/vessel-package-set/releases/download/mo-0.6.21-20220215/package-set.dhall"
+
+// LoadIndex loads the package set at the given location, either a URL or a
+// local file. Locations ending with `.json` are parsed as JSON package sets,
+// all others as Vessel (Dhall) package sets.
+func LoadIndex(location string) (*PackageSet, error) {
+	var (
+		raw []byte
+		err error
+	)
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		raw, err = tar.Fetch(location)
+	} else {
+		raw, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return nil, NewVesselError(err)
+	}
+	if strings.HasSuffix(location, ".json") {
+		return NewJSONPackageSet(raw)
+	}
+	return NewPackageSet(raw)
+}
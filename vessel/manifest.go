@@ -1,10 +1,10 @@
 package vessel
 
 import (
-	"os"
+	"fmt"
+	"sort"
 
 	"github.com/internet-computer/oko/config"
-	"github.com/philandstuff/dhall-golang/v6"
 )
 
 type Manifest struct {
@@ -13,25 +13,25 @@ type Manifest struct {
 }
 
 func LoadManifest(path string) (*Manifest, error) {
-	raw, err := os.ReadFile(path)
-	if err != nil {
-		return nil, NewVesselError(err)
-	}
-	return NewManifest(raw)
+	return NewResolver().LoadManifest(path)
 }
 
 func NewManifest(raw []byte) (*Manifest, error) {
 	var manifest Manifest
-	if err := dhall.Unmarshal(raw, &manifest); err != nil {
-		return nil, NewVesselError(err)
+	if err := NewResolver().unmarshal("-", raw, &manifest); err != nil {
+		return nil, err
 	}
 	return &manifest, nil
 }
 
+// Oko converts the manifest to an Oko package config. Packages in the given set
+// with the same repository and version become a single package, of which the
+// first name in alphabetical order is the name and the others are alternative
+// names.
 func (m Manifest) Oko(set PackageSet) config.PackageConfig {
 	return config.PackageConfig{
 		CompilerVersion: m.Compiler,
-		Dependencies:    set.Oko(),
+		Dependencies:    foldAlternativeNames(set.Oko()),
 	}
 }
 
@@ -42,3 +42,24 @@ func (m Manifest) Save(path string, set PackageSet) error {
 	}
 	return nil
 }
+
+// foldAlternativeNames merges the packages with the same repository and version.
+func foldAlternativeNames(packages []config.PackageInfoRemote) []config.PackageInfoRemote {
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	var (
+		folded  []config.PackageInfoRemote
+		indices = make(map[string]int) // {repository}@{version} -> index
+	)
+	for _, pkg := range packages {
+		key := fmt.Sprintf("%s@%s", pkg.Repository, pkg.Version)
+		if i, ok := indices[key]; ok {
+			folded[i].AlternativeNames = append(folded[i].AlternativeNames, pkg.Name)
+			continue
+		}
+		indices[key] = len(folded)
+		folded = append(folded, pkg)
+	}
+	return folded
+}
@@ -1,43 +1,67 @@
 package vessel
 
 import (
-	"os"
+	"encoding/json"
+	"sort"
 	"strings"
 
 	"github.com/internet-computer/oko/config"
-	"github.com/philandstuff/dhall-golang/v6"
 )
 
 type Package struct {
-	Name         string   `dhall:"name"`
-	Repo         string   `dhall:"repo"`
-	Version      string   `dhall:"version"`
-	Dependencies []string `dhall:"dependencies"`
+	Name         string   `json:"name"`
+	Repo         string   `json:"repository"`
+	Version      string   `json:"version"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	// Only available in JSON package sets.
+	Description string `json:"description,omitempty"`
 }
 
 type PackageSet struct {
 	Packages map[string]Package
 }
 
+// LoadPackageSet loads the package set at the given path, using the standard
+// Dhall cache for frozen imports.
 func LoadPackageSet(path string) (*PackageSet, error) {
-	raw, err := os.ReadFile(path)
-	if err != nil {
+	return NewResolver().LoadPackageSet(path)
+}
+
+// NewJSONPackageSet parses a package set in the JSON format, i.e. a list of
+// packages with a name, repository, version, dependencies and description.
+func NewJSONPackageSet(raw []byte) (*PackageSet, error) {
+	var packages []Package
+	if err := json.Unmarshal(raw, &packages); err != nil {
 		return nil, NewVesselError(err)
 	}
-	return NewPackageSet(raw)
+	return newPackageSet(packages)
 }
 
+// NewPackageSet parses a package set in the Vessel format, using the standard
+// Dhall cache for frozen imports.
 func NewPackageSet(raw []byte) (*PackageSet, error) {
-	var (
-		set = PackageSet{
-			Packages: make(map[string]Package),
-		}
-		list []Package
-	)
-	if err := dhall.Unmarshal(raw, &list); err != nil {
-		return nil, NewVesselError(err)
-	}
+	return NewResolver().NewPackageSet(raw)
+}
+
+// newDhallPackageSet converts the packages of a Vessel package set.
+func newDhallPackageSet(list []dhallPackage) (*PackageSet, error) {
+	var packages []Package
 	for _, pkg := range list {
+		packages = append(packages, Package{
+			Name:         pkg.Name,
+			Repo:         pkg.Repo,
+			Version:      pkg.Version,
+			Dependencies: pkg.Dependencies,
+		})
+	}
+	return newPackageSet(packages)
+}
+
+func newPackageSet(packages []Package) (*PackageSet, error) {
+	set := PackageSet{
+		Packages: make(map[string]Package),
+	}
+	for _, pkg := range packages {
 		if v, ok := set.Packages[pkg.Name]; ok {
 			return &set, DuplicatePackageName(pkg, v)
 		}
@@ -78,3 +102,28 @@ func (set PackageSet) Oko() []config.PackageInfoRemote {
 	}
 	return packages
 }
+
+// Search returns all packages of which the name or description contains the
+// given query (case-insensitive), sorted by name.
+func (set PackageSet) Search(query string) []Package {
+	query = strings.ToLower(query)
+	var packages []Package
+	for _, pkg := range set.Packages {
+		if strings.Contains(strings.ToLower(pkg.Name), query) ||
+			strings.Contains(strings.ToLower(pkg.Description), query) {
+			packages = append(packages, pkg)
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages
+}
+
+// dhallPackage is a package in the Vessel package set format.
+type dhallPackage struct {
+	Name         string   `dhall:"name"`
+	Repo         string   `dhall:"repo"`
+	Version      string   `dhall:"version"`
+	Dependencies []string `dhall:"dependencies"`
+}
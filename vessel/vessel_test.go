@@ -7,13 +7,168 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"reflect"
 	"testing"
 
+	"github.com/internet-computer/oko/config"
 	"github.com/internet-computer/oko/vessel"
 )
 
 const TEST_DIR = "e2e"
 
+func ExamplePackageSet_Search() {
+	set, _ := vessel.LoadIndex("testdata/package-set.json")
+	for _, pkg := range set.Search("motoko") {
+		fmt.Println(pkg.Name, pkg.Version)
+	}
+	// Output:
+	// base moc-0.7.4
+	// testing v0.1.0
+}
+
+func TestExport(t *testing.T) {
+	manifest, err := vessel.LoadManifest("testdata/vessel.dhall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := vessel.LoadPackageSet("testdata/package-set.dhall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	packages, err := set.Filter(manifest.Dependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Migrate to Oko and back.
+	pkg := manifest.Oko(packages)
+	state := config.NewPackageState(&pkg)
+	if len(state.Dependencies) != 2 {
+		t.Fatal(state)
+	}
+	exportedManifest, err := vessel.NewManifest(vessel.NewManifestFromState(*state).MarshalDhall())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(manifest, exportedManifest) {
+		t.Error(manifest, exportedManifest)
+	}
+	exportedSet, err := vessel.NewPackageSet(vessel.NewPackageSetFromState(*state).MarshalDhall())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(set, exportedSet) {
+		t.Error(set, exportedSet)
+	}
+}
+
+func TestExport_alternativeNames(t *testing.T) {
+	state := config.EmptyState()
+	for _, name := range []string{"base", "std"} {
+		if err := state.AddPackage(config.PackageInfoRemote{
+			Name:       name,
+			Repository: "https://github.com/dfinity/motoko-base",
+			Version:    "moc-0.7.4",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := state.AddPackage(config.PackageInfoRemote{
+		Name:         "testing",
+		Repository:   "https://github.com/internet-computer/testing.mo",
+		Version:      "v0.1.0",
+		Dependencies: []string{"std"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Export to Vessel and migrate back.
+	manifest, err := vessel.NewManifest(vessel.NewManifestFromState(state).MarshalDhall())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(manifest.Dependencies, []string{"base", "testing"}) {
+		t.Error(manifest.Dependencies)
+	}
+	set, err := vessel.NewPackageSet(vessel.NewPackageSetFromState(state).MarshalDhall())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Packages) != 3 {
+		t.Error(set.Packages)
+	}
+	packages, err := set.Filter(manifest.Dependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := manifest.Oko(packages)
+	expected, _ := state.MarshalJSON()
+	actual, _ := config.NewPackageState(&pkg).MarshalJSON()
+	if string(expected) != string(actual) {
+		t.Error(string(actual))
+	}
+}
+
+func TestLoadIndex(t *testing.T) {
+	for _, path := range []string{
+		"testdata/package-set.dhall",
+		"testdata/package-set.json",
+	} {
+		set, err := vessel.LoadIndex(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packages, err := set.Filter([]string{"testing"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(packages.Packages) != 2 {
+			t.Error(packages)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	cache := t.TempDir()
+
+	// Unresolved imports.
+	if _, err := (vessel.Resolver{
+		CacheDir: cache,
+		Offline:  true,
+	}).LoadPackageSet("testdata/frozen.dhall"); !errors.As(err, new(*vessel.OfflineError)) {
+		t.Fatal(err)
+	}
+
+	// Mirrored imports.
+	set, err := vessel.Resolver{
+		CacheDir:  cache,
+		MirrorDir: "testdata/mirror",
+		Offline:   true,
+	}.LoadPackageSet("testdata/frozen.dhall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := set.Filter([]string{"testing"}); err != nil {
+		t.Error(err)
+	}
+
+	// Cached imports.
+	if _, err := (vessel.Resolver{
+		CacheDir: cache,
+		Offline:  true,
+	}).LoadPackageSet("testdata/frozen.dhall"); err != nil {
+		t.Error(err)
+	}
+
+	// Integrity check.
+	if _, err := (vessel.Resolver{
+		MirrorDir: "testdata/mirror",
+		Offline:   true,
+	}).LoadPackageSet("testdata/tampered.dhall"); !errors.As(err, new(*vessel.IntegrityError)) {
+		t.Error(err)
+	}
+}
+
 func TestVessel(t *testing.T) {
 	path, err := exec.LookPath("vessel")
 	if err != nil {